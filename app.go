@@ -19,8 +19,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strconv"
-	"strings"
+	"os"
 	"sync"
 	"time"
 
@@ -36,24 +35,29 @@ type App struct {
 	connects sync.Map
 	logger   *Logger
 	debug    bool
+	schemas  *SchemaCache
+	traces   *traceRecorder
+
+	// storeMu is held for writing while a restore or relocation swaps the config store,
+	// the log file and the data directory
+	storeMu       sync.RWMutex
+	confirmations sync.Map // Confirm token -> *pendingConfirmation
+	stopBackups   context.CancelFunc
+	recovery      *RecoveryReport // Set when a corrupted config store was salvaged at startup
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
 	var app = &App{}
 
+	workDirectory, dataDirectorySource = resolveWorkDirectory(os.Args[1:], os.Getenv)
 	if err := CreateWorkDirectory(); err != nil {
 		panic("create work directory failed: " + err.Error())
 	}
 
 	app.logger = NewLogger()
-
-	database, err := ConnectDatabase()
-	if err != nil {
-		app.logger.Error("open database failed", "reason", err)
-		panic("open database failed")
-	}
-	app.db = database
+	app.schemas = NewSchemaCache()
+	app.traces = newTraceRecorder(app)
 
 	return app
 }
@@ -62,15 +66,33 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (app *App) startup(ctx context.Context) {
 	app.ctx = ctx
+	// The store is opened here, after the single instance lock of the runtime handed a
+	// second launch over to the running instance
+	if err := app.openDatabase(); err != nil {
+		_, _ = runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
+			Type:    runtime.ErrorDialog,
+			Title:   "openGemini Studio cannot start",
+			Message: fmt.Sprintf("Opening %s failed: %s", databasePath(), err),
+		})
+		runtime.Quit(ctx)
+		return
+	}
 	setting, err := app.GetSetting()
 	if err != nil {
 		app.logger.Error("get setting failed", "reason", err)
 	} else {
-		app.debug = setting.Debug
+		app.applySetting(setting)
 	}
+	backupCtx, cancel := context.WithCancel(ctx)
+	app.stopBackups = cancel
+	app.startBackups(backupCtx)
 }
 
 func (app *App) shutdown(ctx context.Context) {
+	if app.stopBackups != nil {
+		app.stopBackups()
+	}
+
 	// Close all HTTP client connections first
 	app.connects.Range(func(key, value interface{}) bool {
 		if client, ok := value.(HttpClient); ok {
@@ -81,6 +103,8 @@ func (app *App) shutdown(ctx context.Context) {
 	})
 
 	// Close database connection
+	app.storeMu.Lock()
+	defer app.storeMu.Unlock()
 	if app.db != nil {
 		if err := app.db.Close(); err != nil {
 			app.logger.Error("close database failed", "reason", err)
@@ -96,7 +120,7 @@ func (app *App) shutdown(ctx context.Context) {
 
 func (app *App) ListConnects() []*ConnectConfig {
 	var connects []*ConnectConfig
-	err := app.db.View(func(tx *bolt.Tx) error {
+	err := app.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(BucketConnections))
 		err := bucket.ForEach(func(k, v []byte) error {
 			var connect = &ConnectConfig{}
@@ -130,7 +154,7 @@ func (app *App) AddConnect(cc *ConnectConfig) error {
 		app.logger.Error("get connect failed", "name", cc.Name)
 		return err
 	}
-	err = app.db.Update(func(tx *bolt.Tx) error {
+	err = app.update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(BucketConnections))
 		existConnect := bucket.Get([]byte(cc.Name))
 		if existConnect != nil {
@@ -157,7 +181,7 @@ func (app *App) UpdateConnect(name string, cc *ConnectConfig) error {
 		app.logger.Error("get exist connect failed", "name", name, "err", err)
 		return err
 	}
-	err = app.db.Update(func(tx *bolt.Tx) error {
+	err = app.update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(BucketConnections))
 		existConnect := bucket.Get([]byte(name))
 		if existConnect == nil {
@@ -179,7 +203,7 @@ func (app *App) UpdateConnect(name string, cc *ConnectConfig) error {
 }
 
 func (app *App) DeleteConnect(name string) error {
-	err := app.db.Update(func(tx *bolt.Tx) error {
+	err := app.update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(BucketConnections))
 		return bucket.Delete([]byte(name))
 	})
@@ -188,11 +212,11 @@ func (app *App) DeleteConnect(name string) error {
 
 func (app *App) GetConnect(name string) (*ConnectConfig, error) {
 	var connect = &ConnectConfig{}
-	err := app.db.View(func(tx *bolt.Tx) error {
+	err := app.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(BucketConnections))
 		content := bucket.Get([]byte(name))
 		if content == nil {
-			return errors.New("connection not found")
+			return ConnectNotExistError
 		}
 		err := json.Unmarshal(content, connect)
 		return err
@@ -205,29 +229,62 @@ func (app *App) GetConnect(name string) (*ConnectConfig, error) {
 }
 
 func (app *App) UpdateSetting(settings *AppSetting) error {
-	app.debug = settings.Debug
+	if err := checkLogSetting(settings.Log); err != nil {
+		return err
+	}
+	if settings.DataDirectory != "" && absPath(settings.DataDirectory) != app.GetDataDirectory().Path {
+		if err := app.RelocateDataDirectory(settings.DataDirectory); err != nil {
+			return err
+		}
+	}
+	settings.DataDirectory = app.GetDataDirectory().Path
 	data, err := json.Marshal(settings)
 	if err != nil {
 		app.logger.Error("update settings failed", "reason", err)
 		return err
 	}
-	err = app.db.Update(func(tx *bolt.Tx) error {
+	err = app.update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(BucketSettings))
 		return bucket.Put([]byte("system"), data)
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	app.applySetting(settings)
+	return nil
+}
+
+// applySetting applies the settings that take effect at runtime
+func (app *App) applySetting(setting *AppSetting) {
+	app.debug = setting.Debug
+	app.connects.Range(func(_, value any) bool {
+		if client, ok := value.(HttpClient); ok {
+			client.SetDebug(setting.Debug)
+		}
+		return true
+	})
+	if err := app.logger.Configure(setting.Log, setting.Debug); err != nil {
+		app.logger.Error("configure logger failed", "reason", err)
+	}
 }
 
 func (app *App) GetSetting() (*AppSetting, error) {
 	var setting = &AppSetting{}
-	err := app.db.Update(func(tx *bolt.Tx) error {
+	// A read transaction, write transactions advance the transaction ID that periodic
+	// backups compare to skip unchanged stores
+	err := app.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(BucketSettings))
 		data := bucket.Get([]byte("system"))
 		if data == nil {
 			app.logger.Error("get settings failed", "reason", "setting not found")
 			return errors.New("setting not found")
 		}
-		return json.Unmarshal(data, setting)
+		if err := json.Unmarshal(data, setting); err != nil {
+			return err
+		}
+		// The store belongs to the work directory, both are swapped together
+		setting.DataDirectory = workDirectory
+		return nil
 	})
 	return setting, err
 }
@@ -247,6 +304,7 @@ func (app *App) DialConnect(name string) ([]string, error) {
 		}
 	}
 	app.connects.Delete(name)
+	app.schemas.Invalidate(name)
 
 	app.logger.Info("dial connect", "name", name)
 	cc, err := app.GetConnect(name)
@@ -262,6 +320,7 @@ func (app *App) DialConnect(name string) ([]string, error) {
 		app.logger.Error("dial connect failed: create http client failed", "reason", err)
 		return nil, err
 	}
+	httpClient.SetTracer(app.traces)
 	err = httpClient.Ping()
 	if err != nil {
 		httpClient.Close()
@@ -303,7 +362,25 @@ func (app *App) GetDatabaseMetadata(connectName, databaseName string) (*Database
 		return nil, err
 	}
 
-	return &DatabaseMetadata{RetentionPolicy: policies, Measurements: measurements}, nil
+	// Engine types, downsamples and streams are openGemini specific, other servers reject
+	// these statements. Engine types take a statement per measurement, they are cached.
+	measurementInfo, _ := app.schemas.MeasurementInfo(app.ctx, httpClient, connectName, databaseName, measurements, app.logger)
+	downsamples, err := showDownsamples(app.ctx, httpClient, databaseName)
+	if err != nil {
+		app.logger.Warn("get downsamples failed", "reason", err, "db", databaseName)
+	}
+	streams, err := showStreams(app.ctx, httpClient, databaseName)
+	if err != nil {
+		app.logger.Warn("get streams failed", "reason", err, "db", databaseName)
+	}
+
+	return &DatabaseMetadata{
+		RetentionPolicy: policies,
+		Measurements:    measurements,
+		MeasurementInfo: measurementInfo,
+		Downsamples:     downsamples,
+		Streams:         streams,
+	}, nil
 }
 
 func (app *App) CloseConnect(connectName string) {
@@ -315,6 +392,7 @@ func (app *App) CloseConnect(connectName string) {
 		}
 	}
 	app.connects.Delete(connectName)
+	app.schemas.Invalidate(connectName)
 }
 
 func (app *App) ExecuteCommand(data *ExecuteRequest) (*ExecuteResponse, error) {
@@ -332,182 +410,200 @@ func (app *App) ExecuteCommand(data *ExecuteRequest) (*ExecuteResponse, error) {
 
 	app.logger.Debug("request execute command", "data", data.String())
 
-	var startTime = time.Now()
+	parsed, err := prepareCommand(data, time.Now())
+	if err != nil {
+		app.logger.Error("prepare command failed", "reason", err, "command", data.Command)
+		return nil, err
+	}
+	confirmation, err := app.guardStatements(data.ConnectName, data.Database, data.RetentionPolicy, data.ConfirmToken, parsed.Statements)
+	if err != nil {
+		return nil, err
+	}
+	if confirmation != nil {
+		return &ExecuteResponse{NoContent: true, Message: "confirmation required", Confirmation: confirmation}, nil
+	}
 
-	if strings.HasPrefix(strings.ToLower(data.Command), "insert") {
-		if data.Database == "" {
-			return nil, errors.New("database required")
-		}
-		if data.RetentionPolicy == "" {
-			data.RetentionPolicy = "autogen"
-		}
-		if data.Precision == "" {
-			data.Precision = "ns"
-		}
-		lineProtocol := strings.TrimSpace(data.Command[6:])
-		err := httpClient.Write(app.ctx, data.Database, data.RetentionPolicy, lineProtocol, data.Precision)
-		executionTime := time.Since(startTime).Milliseconds()
-
-		// Save history record
-		history := &History{
-			ID:              strconv.FormatInt(time.Now().UnixMilli(), 10),
-			Query:           data.Command,
-			Timestamp:       time.Now().UnixMilli(),
-			ExecutionTime:   float64(executionTime),
-			Database:        data.Database,
-			RetentionPolicy: data.RetentionPolicy,
-			Success:         err == nil,
+	var (
+		startTime = time.Now()
+		response  *ExecuteResponse
+		stats     = &ExecutionStats{}
+	)
+	for _, stmt := range parsed.Statements {
+		response, err = app.executeStatement(httpClient, data, stmt, stats)
+		if stmt.Category != CategoryRead {
+			// Schema may change even when the statement reports an error
+			app.schemas.Invalidate(data.ConnectName)
 		}
 		if err != nil {
-			history.Error = err.Error()
-			app.logger.Error("execute command failed", "reason", err, "command", data.Command)
-			_ = app.AddHistory(history)
-			return nil, err
+			break
 		}
-		_ = app.AddHistory(history)
-		return &ExecuteResponse{NoContent: true, Message: "write success", ExecutionTime: float64(executionTime)}, nil
 	}
-	response, err := httpClient.Query(app.ctx, &opengemini.Query{
-		Database:        data.Database,
-		Command:         data.Command,
-		RetentionPolicy: data.RetentionPolicy,
-		Precision:       opengemini.ToPrecision(data.Precision),
-	})
-	executionTime := time.Since(startTime).Milliseconds()
+	executionTime := float64(time.Since(startTime).Microseconds()) / 1000
 
 	// Save history record
 	history := &History{
-		ID:              strconv.FormatInt(time.Now().UnixMilli(), 10),
+		ID:              newHistoryID(time.Now()),
 		Query:           data.Command,
 		Timestamp:       time.Now().UnixMilli(),
-		ExecutionTime:   float64(executionTime),
+		ExecutionTime:   executionTime,
 		Database:        data.Database,
 		RetentionPolicy: data.RetentionPolicy,
-		Success:         false,
+		Success:         err == nil,
+		ConnectName:     data.ConnectName,
+		Stats:           stats,
 	}
-
 	if err != nil {
 		history.Error = err.Error()
 		app.logger.Error("execute command failed", "reason", err, "name", data.ConnectName)
 		_ = app.AddHistory(history)
 		return nil, err
 	}
-	if response.Error != "" {
-		history.Error = response.Error
-		app.logger.Error("execute command failed", "reason", response.Error)
-		_ = app.AddHistory(history)
-		return nil, fmt.Errorf("execute command failed: %s", response.Error)
-	}
-	if len(response.Results) == 0 {
-		history.Success = true
-		_ = app.AddHistory(history)
-		return &ExecuteResponse{NoContent: true, ExecutionTime: float64(executionTime)}, nil
-	}
-
-	if response.Results[0].Error != "" {
-		history.Error = response.Results[0].Error
-		app.logger.Error("execute command failed", "reason", response.Results[0].Error)
-		_ = app.AddHistory(history)
-		return nil, fmt.Errorf("execute command failed: %s", response.Results[0].Error)
-	}
-
-	if len(response.Results[0].Series) == 0 {
-		history.Success = true
-		_ = app.AddHistory(history)
-		return &ExecuteResponse{NoContent: true, ExecutionTime: float64(executionTime)}, nil
-	}
-
-	var (
-		seriesValues = response.Results[0].Series[0].Values
-		columns      = response.Results[0].Series[0].Columns
-		values       = make([][]any, 0, len(seriesValues))
-	)
-	for _, v := range seriesValues {
-		// Directly append the row data from seriesValues
-		values = append(values, v)
-	}
-
-	history.Success = true
 	_ = app.AddHistory(history)
-	return &ExecuteResponse{NoContent: false, Columns: columns, Values: values, ExecutionTime: float64(executionTime)}, nil
+
+	response.ExecutionTime = executionTime
+	return response, nil
 }
 
-func (app *App) GetHistories() ([]*History, error) {
-	var histories []*History
-	err := app.db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(BucketHistories))
-		err := bucket.ForEach(func(k, v []byte) error {
-			var history = &History{}
-			err := json.Unmarshal(v, history)
-			if err != nil {
-				return err
-			}
-			histories = append(histories, history)
-			return nil
-		})
-		return err
-	})
+// prepareCommand expands the variables of the command, adds the time range of the request
+// to the SELECT statements lacking one and parses the result. Syntax errors are reported
+// before anything is sent unless the request ignores them, see checkSyntax.
+func prepareCommand(data *ExecuteRequest, now time.Time) (*ParseResult, error) {
+	command, err := ExpandVariables(data.Command, data.Variables, data.TimeRange, now)
 	if err != nil {
-		app.logger.Error("list histories failed", "reason", err)
 		return nil, err
 	}
-
-	// Sort by timestamp descending (newest first)
-	for i := 0; i < len(histories)-1; i++ {
-		for j := i + 1; j < len(histories); j++ {
-			if histories[i].Timestamp < histories[j].Timestamp {
-				histories[i], histories[j] = histories[j], histories[i]
+	parsed := ParseInfluxQL(command)
+	if err := checkSyntax(parsed, data.IgnoreSyntaxErrors); err != nil {
+		return nil, err
+	}
+	if data.TimeRange != nil && data.TimeRange.From != "" {
+		injected, err := InjectTimeRange(command, parsed, data.TimeRange, now)
+		if err != nil {
+			return nil, err
+		}
+		if injected != command {
+			parsed = ParseInfluxQL(injected)
+			if err := checkSyntax(parsed, data.IgnoreSyntaxErrors); err != nil {
+				return nil, err
 			}
 		}
 	}
+	if len(parsed.Statements) == 0 {
+		return nil, errors.New("command required")
+	}
+	return parsed, nil
+}
 
-	// Limit to maxHistoryCount from settings
-	settings, err := app.GetSetting()
-	if err == nil && settings.MaxHistoryCount > 0 && len(histories) > settings.MaxHistoryCount {
-		histories = histories[:settings.MaxHistoryCount]
+// checkSyntax returns the first syntax error of the statements. When ignoreErrors is set
+// for syntax the parser does not know, statements with errors are sent as written and left
+// to the safe mode, their category is unknown. INSERT statements without line protocol are
+// always refused, they are written by the client rather than checked by the server.
+func checkSyntax(parsed *ParseResult, ignoreErrors bool) error {
+	for _, stmt := range parsed.Statements {
+		if stmt.Error != nil && (!ignoreErrors || stmt.Insert != nil) {
+			return stmt.Error
+		}
 	}
+	return nil
+}
 
-	return histories, nil
+// ParseQuery splits the query text into statements and classifies them without sending
+// anything to the server
+func (app *App) ParseQuery(text string) *ParseResult {
+	return ParseInfluxQL(text)
 }
 
-func (app *App) AddHistory(history *History) error {
-	data, err := json.Marshal(history)
-	if err != nil {
-		app.logger.Error("marshal history failed", "reason", err)
-		return err
+// executeStatement runs a single parsed statement, INSERT statements go through the
+// write endpoint and everything else through the query endpoint
+func (app *App) executeStatement(httpClient HttpClient, data *ExecuteRequest, stmt *Statement, stats *ExecutionStats) (*ExecuteResponse, error) {
+	stats.Statements++
+	if stmt.Insert != nil {
+		if data.Database == "" {
+			return nil, errors.New("database required")
+		}
+		var (
+			retentionPolicy = data.RetentionPolicy
+			precision       = data.Precision
+		)
+		if stmt.Insert.RetentionPolicy != "" {
+			retentionPolicy = stmt.Insert.RetentionPolicy
+		}
+		if retentionPolicy == "" {
+			retentionPolicy = "autogen"
+		}
+		if precision == "" {
+			precision = "ns"
+		}
+		err := httpClient.Write(app.ctx, data.Database, retentionPolicy, stmt.Insert.LineProtocol, precision)
+		if err != nil {
+			return nil, err
+		}
+		return &ExecuteResponse{NoContent: true, Message: "write success"}, nil
 	}
 
-	err = app.db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(BucketHistories))
-		return bucket.Put([]byte(history.ID), data)
-	})
+	var (
+		precision = opengemini.ToPrecision(data.Precision)
+		formatter *timeColumnFormatter
+	)
+	if format := app.resultFormat(data); format != nil {
+		var location string
+		if stmt.Select != nil {
+			location = stmt.Select.Location
+		}
+		var err error
+		if formatter, err = newTimeColumnFormatter(format, location); err != nil {
+			return nil, err
+		}
+		precision = opengemini.PrecisionRFC3339
+	}
 
+	response, profile, err := httpClient.QueryProfiled(app.ctx, &opengemini.Query{
+		Database:        data.Database,
+		Command:         stmt.Text,
+		RetentionPolicy: data.RetentionPolicy,
+		Precision:       precision,
+	})
+	if profile != nil {
+		stats.TimeToFirstByte += profile.TimeToFirstByte
+		stats.ResponseBytes += profile.ResponseBytes
+		stats.ServerVersion = profile.ServerVersion
+	}
 	if err != nil {
-		app.logger.Error("save history failed", "reason", err)
-		return err
+		return nil, err
 	}
-
-	// Clean up old histories if exceeds max count
-	settings, err := app.GetSetting()
-	if err == nil && settings.MaxHistoryCount > 0 {
-		histories, err := app.GetHistories()
-		if err == nil && len(histories) > settings.MaxHistoryCount {
-			// Delete oldest histories
-			toDelete := histories[settings.MaxHistoryCount:]
-			err = app.db.Update(func(tx *bolt.Tx) error {
-				bucket := tx.Bucket([]byte(BucketHistories))
-				for _, h := range toDelete {
-					if err := bucket.Delete([]byte(h.ID)); err != nil {
-						return err
-					}
-				}
-				return nil
-			})
-			if err != nil {
-				app.logger.Error("delete old histories failed", "reason", err)
-			}
+	for _, result := range response.Results {
+		stats.Series += len(result.Series)
+		for _, series := range result.Series {
+			stats.Rows += int64(len(series.Values))
 		}
 	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("execute command failed: %s", response.Error)
+	}
+	if len(response.Results) == 0 {
+		return &ExecuteResponse{NoContent: true}, nil
+	}
+	if response.Results[0].Error != "" {
+		return nil, fmt.Errorf("execute command failed: %s", response.Results[0].Error)
+	}
+	if len(response.Results[0].Series) == 0 {
+		return &ExecuteResponse{NoContent: true}, nil
+	}
 
-	return nil
+	var (
+		seriesValues = response.Results[0].Series[0].Values
+		columns      = response.Results[0].Series[0].Columns
+		values       = make([][]any, 0, len(seriesValues))
+	)
+	for _, v := range seriesValues {
+		// Directly append the row data from seriesValues
+		values = append(values, v)
+	}
+	result := &ExecuteResponse{NoContent: false, Columns: columns, Values: values}
+	if formatter != nil {
+		formatter.apply(columns, values)
+		result.Timezone = formatter.location.String()
+		result.TimeFormat = formatter.format
+	}
+	return result, nil
 }
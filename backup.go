@@ -0,0 +1,307 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	BackupReasonAuto      = "auto"
+	BackupReasonMigration = "migration"
+	BackupReasonRestore   = "restore" // The store replaced by a restore
+
+	backupDirectory  = "backups"
+	backupTimeLayout = "20060102-150405.000"
+	backupInterval   = time.Hour
+	// maxAutoBackups periodic snapshots are kept, the others are only removed by hand
+	maxAutoBackups = 10
+)
+
+func databasePath() string {
+	return filepath.Join(workDirectory, "config.db")
+}
+
+func backupDirectoryPath() string {
+	return filepath.Join(workDirectory, backupDirectory)
+}
+
+// backupDatabase writes a consistent snapshot of the store to the backups folder and
+// returns its path. Snapshots are written to a temporary file first, a crash never leaves
+// a truncated backup behind.
+func backupDatabase(db *bolt.DB, reason string) (string, error) {
+	dir := backupDirectoryPath()
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("config-%s-%s.db", time.Now().Format(backupTimeLayout), reason)
+	path := filepath.Join(dir, name)
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(file)
+		return err
+	})
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return path, nil
+}
+
+// startBackups snapshots the store at startup and every backupInterval until ctx is done,
+// skipping the snapshot when nothing was written since the previous one
+func (app *App) startBackups(ctx context.Context) {
+	go func() {
+		var (
+			lastTx = -1
+			ticker = time.NewTicker(backupInterval)
+		)
+		defer ticker.Stop()
+		for {
+			lastTx = app.autoBackup(lastTx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// autoBackup snapshots the store unless its last transaction is lastTx, and returns the
+// transaction of the latest snapshot. The store is not swapped while it is written.
+func (app *App) autoBackup(lastTx int) int {
+	app.storeMu.RLock()
+	defer app.storeMu.RUnlock()
+	var txID int
+	if err := app.db.View(func(tx *bolt.Tx) error { txID = tx.ID(); return nil }); err != nil {
+		app.logger.Warn("backup config store skipped", "reason", err)
+		return lastTx
+	}
+	if txID == lastTx {
+		return lastTx
+	}
+	path, err := backupDatabase(app.db, BackupReasonAuto)
+	if err != nil {
+		app.logger.Error("backup config store failed", "reason", err)
+		return lastTx
+	}
+	app.logger.Debug("backup config store", "path", path)
+	app.rotateBackups()
+	return txID
+}
+
+// rotateBackups removes the oldest periodic snapshots beyond maxAutoBackups
+func (app *App) rotateBackups() {
+	backups, err := listBackups()
+	if err != nil {
+		app.logger.Warn("list backups failed", "reason", err)
+		return
+	}
+	var count int
+	for _, backup := range backups {
+		if backup.Reason != BackupReasonAuto {
+			continue
+		}
+		if count++; count > maxAutoBackups {
+			if err := os.Remove(filepath.Join(backupDirectoryPath(), backup.Name)); err != nil {
+				app.logger.Warn("remove backup failed", "reason", err, "name", backup.Name)
+			}
+		}
+	}
+}
+
+// ListBackups returns the snapshots of the backups folder, newest first
+func (app *App) ListBackups() ([]*BackupInfo, error) {
+	app.storeMu.RLock()
+	defer app.storeMu.RUnlock()
+	return listBackups()
+}
+
+func listBackups() ([]*BackupInfo, error) {
+	entries, err := os.ReadDir(backupDirectoryPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return make([]*BackupInfo, 0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backups = make([]*BackupInfo, 0, len(entries))
+	for _, entry := range entries {
+		stamp, reason, ok := parseBackupName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		createdAt, err := time.ParseInLocation(backupTimeLayout, stamp, time.Local)
+		if err != nil {
+			createdAt = info.ModTime()
+		}
+		backups = append(backups, &BackupInfo{
+			Name:      entry.Name(),
+			Reason:    reason,
+			Size:      info.Size(),
+			CreatedAt: createdAt.UnixMilli(),
+		})
+	}
+	// Names start with the time, their order is chronological
+	slices.SortFunc(backups, func(a, b *BackupInfo) int { return strings.Compare(b.Name, a.Name) })
+	return backups, nil
+}
+
+// parseBackupName splits "config-<time>-<reason>.db"
+func parseBackupName(name string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(name, "config-")
+	if !ok {
+		return "", "", false
+	}
+	if rest, ok = strings.CutSuffix(rest, ".db"); !ok || len(rest) < len(backupTimeLayout)+2 {
+		return "", "", false
+	}
+	return rest[:len(backupTimeLayout)], rest[len(backupTimeLayout)+1:], true
+}
+
+// validateBackup opens the snapshot read only and checks its consistency, its buckets and
+// that this build can migrate its schema version
+func validateBackup(path string) error {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("open backup failed: %w", err)
+	}
+	defer db.Close()
+	return db.View(func(tx *bolt.Tx) error {
+		if err := checkConsistency(tx); err != nil {
+			return fmt.Errorf("backup is corrupted: %w", err)
+		}
+		for _, name := range []string{BucketConnections, BucketSettings} {
+			if tx.Bucket([]byte(name)) == nil {
+				return fmt.Errorf("backup has no %s bucket", name)
+			}
+		}
+		version, err := readSchemaVersion(tx)
+		if err != nil {
+			return err
+		}
+		if version > schemaVersion() {
+			return fmt.Errorf("backup schema version %d is newer than the supported version %d", version, schemaVersion())
+		}
+		return nil
+	})
+}
+
+// RestoreBackup validates the snapshot and swaps it in for config.db. The current store is
+// backed up first, so that a restore can be undone by restoring that backup.
+func (app *App) RestoreBackup(name string) error {
+	if name != filepath.Base(name) {
+		return fmt.Errorf("invalid backup name: %s", name)
+	}
+	if _, _, ok := parseBackupName(name); !ok {
+		return fmt.Errorf("invalid backup name: %s", name)
+	}
+	previous, err := app.restoreDatabase(name)
+	if err != nil {
+		return err
+	}
+	if setting, err := app.GetSetting(); err == nil {
+		app.applySetting(setting)
+	}
+	app.logger.Info("restore config store", "name", name, "previous", filepath.Base(previous))
+	return nil
+}
+
+// restoreDatabase replaces the store by the backup name and returns the backup of the
+// replaced store. Other callers of the store wait until the swap is over.
+func (app *App) restoreDatabase(name string) (string, error) {
+	app.storeMu.Lock()
+	defer app.storeMu.Unlock()
+
+	path := filepath.Join(backupDirectoryPath(), name)
+	if err := validateBackup(path); err != nil {
+		app.logger.Error("validate backup failed", "reason", err, "name", name)
+		return "", err
+	}
+	previous, err := backupDatabase(app.db, BackupReasonRestore)
+	if err != nil {
+		app.logger.Error("backup config store failed", "reason", err)
+		return "", fmt.Errorf("backup current config store failed: %w", err)
+	}
+
+	// Clients and schemas belong to the connections of the current store
+	app.connects.Range(func(key, value any) bool {
+		app.CloseConnect(key.(string))
+		return true
+	})
+	if err := app.db.Close(); err != nil {
+		return "", fmt.Errorf("close config store failed: %w", err)
+	}
+	err = replaceDatabase(path)
+	db, openErr := ConnectDatabase(app.logger)
+	if openErr != nil && err == nil {
+		// Put the previous store back rather than leaving the application without one
+		app.logger.Error("open restored config store failed", "reason", openErr, "name", name)
+		err = openErr
+		if replaceErr := replaceDatabase(previous); replaceErr == nil {
+			db, openErr = ConnectDatabase(app.logger)
+		}
+	}
+	if openErr != nil {
+		return "", fmt.Errorf("open config store failed: %w", openErr)
+	}
+	app.db = db
+	if err != nil {
+		return "", fmt.Errorf("restore backup failed: %w", err)
+	}
+	return previous, nil
+}
+
+// replaceDatabase copies the snapshot next to config.db and renames it over the store
+func replaceDatabase(snapshot string) error {
+	tmp := databasePath() + ".tmp"
+	_ = os.Remove(tmp)
+	if err := copyPath(snapshot, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, databasePath()); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
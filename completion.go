@@ -0,0 +1,456 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxCompletionItems = 200
+
+const (
+	CompletionKeyword         = "keyword"
+	CompletionFunction        = "function"
+	CompletionDatabase        = "database"
+	CompletionRetentionPolicy = "retention_policy"
+	CompletionMeasurement     = "measurement"
+	CompletionTagKey          = "tag_key"
+	CompletionFieldKey        = "field_key"
+	CompletionTagValue        = "tag_value"
+)
+
+// influxqlFunctions are the functions offered in SELECT fields
+var influxqlFunctions = strings.Fields(`count distinct integral mean median mode spread stddev sum
+	bottom first last max min percentile sample top abs acos asin atan atan2 ceil cos cumulative_sum
+	derivative difference elapsed exp floor histogram ln log log2 log10 moving_average
+	non_negative_derivative non_negative_difference pow round sin sqrt tan holt_winters
+	holt_winters_with_fit chande_momentum_oscillator exponential_moving_average
+	double_exponential_moving_average kaufmans_efficiency_ratio kaufmans_adaptive_moving_average
+	triple_exponential_moving_average triple_exponential_derivative relative_strength_index`)
+
+var (
+	statementKeywords = []string{"SELECT", "SHOW", "INSERT", "DELETE", "CREATE", "DROP", "ALTER", "GRANT",
+		"REVOKE", "EXPLAIN", "KILL QUERY", "SET PASSWORD FOR"}
+	showKeywords = []string{"DATABASES", "MEASUREMENTS", "SERIES", "TAG KEYS", "TAG VALUES", "FIELD KEYS",
+		"RETENTION POLICIES", "CONTINUOUS QUERIES", "USERS", "GRANTS", "SHARDS", "SHARD GROUPS", "STATS",
+		"DIAGNOSTICS", "SUBSCRIPTIONS", "QUERIES", "DOWNSAMPLES", "STREAMS", "MEASUREMENT CARDINALITY",
+		"SERIES CARDINALITY", "TAG KEY CARDINALITY", "TAG VALUES CARDINALITY", "FIELD KEY CARDINALITY"}
+	objectKeywords = []string{"DATABASE", "MEASUREMENT", "RETENTION POLICY", "CONTINUOUS QUERY", "SERIES",
+		"USER", "SUBSCRIPTION", "SHARD", "DOWNSAMPLE", "STREAM"}
+	afterSelectFieldKeywords = []string{"AS", "FROM", "INTO"}
+	afterConditionKeywords   = []string{"AND", "OR", "GROUP BY", "ORDER BY", "LIMIT", "OFFSET", "SLIMIT", "SOFFSET"}
+	afterGroupByKeywords     = []string{"fill", "ORDER BY", "LIMIT", "OFFSET", "SLIMIT", "SOFFSET"}
+	afterSourceKeywords      = []string{"WHERE", "GROUP BY", "ORDER BY", "LIMIT", "OFFSET", "SLIMIT", "SOFFSET"}
+)
+
+// completionContext describes what is expected at the cursor
+type completionContext struct {
+	kind         string
+	prefix       string
+	quote        byte // Quote character when the cursor is inside a quoted token
+	replaceStart int  // Byte offsets of the text replaced by the completion
+	replaceEnd   int
+	database     string
+	measurement  string
+	tagKey       string
+	keywords     []string
+}
+
+// Complete returns completion candidates for the cursor position, using the schema cache
+// of the connection for databases, measurements, keys and tag values
+func (app *App) Complete(req *CompletionRequest) (*CompletionResponse, error) {
+	if req.ConnectName == "" {
+		return nil, errors.New("connect name required")
+	}
+	httpClient, err := app.getDialer(req.ConnectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", req.ConnectName)
+		return nil, err
+	}
+
+	cursor := byteOffset(req.Text, req.Cursor)
+	ctx := analyzeCompletion(req.Text, cursor)
+	database := req.Database
+	if ctx.database != "" && ctx.kind != "qualified" {
+		database = ctx.database
+	}
+
+	var items []*CompletionItem
+	add := func(kind, detail string, labels ...string) {
+		for _, label := range labels {
+			if !strings.HasPrefix(strings.ToLower(label), strings.ToLower(ctx.prefix)) {
+				continue
+			}
+			items = append(items, &CompletionItem{Label: label, Kind: kind, Detail: detail, InsertText: completionInsertText(kind, label, ctx.quote)})
+		}
+	}
+	addTagKeys := func() {
+		if tagKeys, err := app.schemas.TagKeys(app.ctx, httpClient, req.ConnectName, database, ctx.measurement); err == nil {
+			add(CompletionTagKey, "tag", tagKeys...)
+		} else {
+			app.logger.Warn("load tag keys failed", "reason", err, "db", database)
+		}
+	}
+	addFieldKeys := func() {
+		if fieldKeys, err := app.schemas.FieldKeys(app.ctx, httpClient, req.ConnectName, database, ctx.measurement); err == nil {
+			for _, fieldKey := range fieldKeys {
+				add(CompletionFieldKey, fieldKey.Type, fieldKey.Name)
+			}
+		} else {
+			app.logger.Warn("load field keys failed", "reason", err, "db", database)
+		}
+	}
+
+	switch ctx.kind {
+	case "database":
+		databases, err := app.schemas.Databases(app.ctx, httpClient, req.ConnectName)
+		if err != nil {
+			return nil, err
+		}
+		add(CompletionDatabase, "", databases...)
+	case "qualified":
+		// db.<rp> when the qualifier is a database, rp.<measurement> otherwise
+		databases, err := app.schemas.Databases(app.ctx, httpClient, req.ConnectName)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(databases, ctx.database) {
+			policies, err := app.schemas.RetentionPolicies(app.ctx, httpClient, req.ConnectName, ctx.database)
+			if err != nil {
+				return nil, err
+			}
+			for _, policy := range policies {
+				add(CompletionRetentionPolicy, policy.Duration, policy.Name)
+			}
+		} else if req.Database != "" {
+			measurements, err := app.schemas.Measurements(app.ctx, httpClient, req.ConnectName, req.Database)
+			if err != nil {
+				return nil, err
+			}
+			add(CompletionMeasurement, req.Database, measurements...)
+		}
+	case "retention_policy":
+		policies, err := app.schemas.RetentionPolicies(app.ctx, httpClient, req.ConnectName, database)
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policies {
+			add(CompletionRetentionPolicy, policy.Duration, policy.Name)
+		}
+	case "measurement":
+		if database == "" {
+			break
+		}
+		measurements, err := app.schemas.Measurements(app.ctx, httpClient, req.ConnectName, database)
+		if err != nil {
+			return nil, err
+		}
+		add(CompletionMeasurement, database, measurements...)
+	case "select_field":
+		addFieldKeys()
+		addTagKeys()
+		add(CompletionFunction, "", influxqlFunctions...)
+	case "condition":
+		addTagKeys()
+		addFieldKeys()
+		add(CompletionKeyword, "", "time")
+	case "group_by":
+		addTagKeys()
+		add(CompletionFunction, "", "time")
+	case "tag_key":
+		addTagKeys()
+	case "tag_value":
+		values, err := app.schemas.TagValues(app.ctx, httpClient, req.ConnectName, database, ctx.measurement, ctx.tagKey)
+		if err != nil {
+			return nil, err
+		}
+		add(CompletionTagValue, ctx.tagKey, values...)
+	}
+	add(CompletionKeyword, "", ctx.keywords...)
+
+	if len(items) > maxCompletionItems {
+		items = items[:maxCompletionItems]
+	}
+	return &CompletionResponse{
+		Context:      ctx.kind,
+		Items:        items,
+		ReplaceStart: utf8.RuneCountInString(req.Text[:ctx.replaceStart]),
+		ReplaceEnd:   utf8.RuneCountInString(req.Text[:ctx.replaceEnd]),
+	}, nil
+}
+
+// analyzeCompletion determines the completion context at the byte offset cursor
+func analyzeCompletion(text string, cursor int) *completionContext {
+	var (
+		ctx     = &completionContext{replaceStart: cursor, replaceEnd: cursor}
+		scanner = NewScanner(text)
+		tokens  []Token
+	)
+	for {
+		token := scanner.Scan()
+		if token.Type == TokenEOF {
+			break
+		}
+		if token.Type == TokenWS {
+			continue
+		}
+		inside := token.Pos.Offset < cursor && cursor < token.End.Offset
+		if token.Type == TokenComment {
+			if inside || cursor == token.End.Offset && strings.HasPrefix(token.Text, "--") {
+				ctx.kind = "none"
+				return ctx
+			}
+			continue
+		}
+		tokens = append(tokens, token)
+		if token.IsKeyword("INSERT") && (len(tokens) == 1 || tokens[len(tokens)-2].Type == TokenSemicolon || tokens[len(tokens)-2].Type == TokenLineProtocol) {
+			line := scanner.ScanLineProtocol()
+			if line.Pos.Offset < cursor && cursor <= line.End.Offset {
+				ctx.kind = "none"
+				return ctx
+			}
+			tokens = append(tokens, line)
+		}
+	}
+
+	// Restrict to the statement containing the cursor
+	var stmt []Token
+	for _, token := range tokens {
+		if token.Type == TokenSemicolon || token.Type == TokenLineProtocol {
+			if token.End.Offset <= cursor {
+				stmt = nil
+				continue
+			}
+			break
+		}
+		stmt = append(stmt, token)
+	}
+
+	// The token being typed, if any
+	var before = stmt
+	for i, token := range stmt {
+		if token.Pos.Offset >= cursor {
+			before = stmt[:i]
+			break
+		}
+		quoted := token.Type == TokenQuotedIdent || token.Type == TokenString ||
+			token.Type == TokenIllegal && (strings.HasPrefix(token.Text, "'") || strings.HasPrefix(token.Text, `"`))
+		terminated := token.Type != TokenIllegal
+		if quoted && (cursor < token.End.Offset || !terminated) || !quoted && cursor <= token.End.Offset &&
+			(token.Type == TokenIdent || token.Type == TokenKeyword) {
+			before = stmt[:i]
+			ctx.replaceStart, ctx.replaceEnd = token.Pos.Offset, token.End.Offset
+			ctx.prefix = text[token.Pos.Offset:cursor]
+			if quoted {
+				ctx.quote = token.Text[0]
+				ctx.prefix = ctx.prefix[1:]
+			}
+			break
+		}
+	}
+
+	// Qualified measurement names such as db.rp.<cursor>
+	var qualifiers []string
+	for len(before) >= 2 && before[len(before)-1].Type == TokenDot {
+		part := before[len(before)-2]
+		if part.Type == TokenDot {
+			qualifiers = append([]string{""}, qualifiers...)
+			before = before[:len(before)-1]
+			continue
+		}
+		if !part.IsIdent() && part.Type != TokenKeyword {
+			break
+		}
+		qualifiers = append([]string{identValue(part)}, qualifiers...)
+		before = before[:len(before)-2]
+	}
+
+	ctx.database, ctx.measurement = statementSource(stmt)
+	if len(before) == 0 {
+		ctx.kind = "keyword"
+		ctx.keywords = statementKeywords
+		return ctx
+	}
+
+	var (
+		last   = before[len(before)-1]
+		verb   = before[0].Value
+		clause string
+	)
+	for i, token := range before {
+		if token.Type != TokenKeyword {
+			continue
+		}
+		switch token.Value {
+		case "SELECT", "INTO", "FROM", "WHERE", "LIMIT", "OFFSET", "SLIMIT", "SOFFSET", "ON", "WITH":
+			clause = token.Value
+		case "BY":
+			if i > 0 && before[i-1].Type == TokenKeyword {
+				clause = before[i-1].Value + " BY"
+			}
+		}
+	}
+
+	switch {
+	case len(qualifiers) > 0 && (clause == "FROM" || clause == "INTO"):
+		// db.<rp> or rp.<measurement> is resolved against the schema by the caller
+		ctx.kind = "qualified"
+		ctx.database = qualifiers[0]
+		if len(qualifiers) > 1 {
+			ctx.kind = "measurement"
+		}
+	case last.IsKeyword("SHOW"):
+		ctx.kind = "keyword"
+		ctx.keywords = showKeywords
+	case len(before) == 1 && (verb == "DROP" || verb == "CREATE" || verb == "ALTER"):
+		ctx.kind = "keyword"
+		ctx.keywords = objectKeywords
+	case last.IsKeyword("ON"):
+		ctx.kind = "database"
+	case last.IsKeyword("DATABASE") && (verb == "DROP" || verb == "ALTER"):
+		ctx.kind = "database"
+	case last.IsKeyword("MEASUREMENT") && verb == "DROP":
+		ctx.kind = "measurement"
+	case last.IsKeyword("POLICY") && (verb == "DROP" || verb == "ALTER"):
+		ctx.kind = "retention_policy"
+	case last.IsKeyword("FROM") || last.IsKeyword("INTO") || last.Type == TokenComma && clause == "FROM":
+		ctx.kind = "measurement"
+	case clause == "FROM" || clause == "INTO":
+		ctx.kind = "keyword"
+		ctx.keywords = afterSourceKeywords
+	case clause == "WITH" && (last.IsKeyword("KEY") || last.Type == TokenOperator && last.Value == "=" || last.Type == TokenLParen || last.Type == TokenComma):
+		ctx.kind = "tag_key"
+	case clause == "SELECT":
+		if isOperandPosition(last) || last.IsKeyword("SELECT") || last.IsKeyword("DISTINCT") {
+			ctx.kind = "select_field"
+		} else {
+			ctx.kind = "keyword"
+			ctx.keywords = afterSelectFieldKeywords
+		}
+	case clause == "WHERE":
+		if last.Type == TokenOperator && (last.Value == "=" || last.Value == "!=" || last.Value == "<>") &&
+			len(before) >= 2 && before[len(before)-2].IsIdent() {
+			ctx.kind = "tag_value"
+			ctx.tagKey = identValue(before[len(before)-2])
+		} else if isOperandPosition(last) || last.IsKeyword("WHERE") || last.IsKeyword("AND") || last.IsKeyword("OR") {
+			ctx.kind = "condition"
+		} else {
+			ctx.kind = "keyword"
+			ctx.keywords = afterConditionKeywords
+		}
+	case clause == "GROUP BY":
+		if last.IsKeyword("BY") || last.Type == TokenComma {
+			ctx.kind = "group_by"
+		} else {
+			ctx.kind = "keyword"
+			ctx.keywords = afterGroupByKeywords
+		}
+	case clause == "ORDER BY":
+		if last.IsKeyword("BY") {
+			ctx.kind = "keyword"
+			ctx.keywords = []string{"time"}
+		} else {
+			ctx.kind = "keyword"
+			ctx.keywords = []string{"ASC", "DESC", "LIMIT", "OFFSET", "SLIMIT", "SOFFSET"}
+		}
+	default:
+		ctx.kind = "keyword"
+	}
+
+	return ctx
+}
+
+// statementSource returns the database and measurement of the first FROM clause
+func statementSource(stmt []Token) (database, measurement string) {
+	for i, token := range stmt {
+		if !token.IsKeyword("FROM") {
+			continue
+		}
+		var parts []string
+		for j := i + 1; j < len(stmt); j++ {
+			part := stmt[j]
+			switch {
+			case part.IsIdent() || part.Type == TokenKeyword && !structuralKeywords[part.Value]:
+				parts = append(parts, identValue(part))
+			case part.Type == TokenDot && j > i+1 && stmt[j-1].Type == TokenDot:
+				parts = append(parts, "")
+			case part.Type == TokenDot:
+			default:
+				j = len(stmt)
+				continue
+			}
+			if j+1 < len(stmt) && stmt[j+1].Type != TokenDot && part.Type != TokenDot {
+				break
+			}
+		}
+		switch len(parts) {
+		case 0:
+			continue
+		case 3:
+			return parts[0], parts[2]
+		default:
+			return "", parts[len(parts)-1]
+		}
+	}
+	return "", ""
+}
+
+// isOperandPosition reports whether an operand is expected after token
+func isOperandPosition(token Token) bool {
+	switch token.Type {
+	case TokenOperator, TokenLParen, TokenComma:
+		return true
+	}
+	return false
+}
+
+func identValue(token Token) string {
+	if token.Type == TokenQuotedIdent {
+		return token.Value
+	}
+	return token.Text
+}
+
+func completionInsertText(kind, label string, quote byte) string {
+	switch kind {
+	case CompletionKeyword:
+		return label
+	case CompletionFunction:
+		return label + "()"
+	case CompletionTagValue:
+		return quoteString(label)
+	}
+	if quote == '"' {
+		return quoteIdentifier(label)
+	}
+	return formatIdentifier(label)
+}
+
+// byteOffset converts a character offset into a byte offset of text
+func byteOffset(text string, characters int) int {
+	if characters <= 0 {
+		return 0
+	}
+	for i := range text {
+		if characters == 0 {
+			return i
+		}
+		characters--
+	}
+	return len(text)
+}
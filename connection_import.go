@@ -0,0 +1,384 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	bolt "go.etcd.io/bbolt"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ImportSourceEnv      = "env"
+	ImportSourceGrafana  = "grafana"
+	ImportSourceTelegraf = "telegraf"
+
+	defaultInfluxPort = "8086"
+)
+
+// PreviewConnectionImport reads the connections defined by an influx CLI environment, a
+// Grafana datasource provisioning file or a Telegraf configuration, without saving them
+func (app *App) PreviewConnectionImport(req *ConnectionImportRequest) ([]*ConnectionCandidate, error) {
+	var (
+		candidates []*ConnectionCandidate
+		err        error
+	)
+	switch req.Source {
+	case ImportSourceEnv:
+		candidates = envCandidates(os.LookupEnv)
+	case ImportSourceGrafana:
+		candidates, err = grafanaCandidates(req.Path)
+	case ImportSourceTelegraf:
+		candidates, err = telegrafCandidates(req.Path)
+	default:
+		return nil, fmt.Errorf("unsupported import source: %s", req.Source)
+	}
+	if err != nil {
+		app.logger.Error("read connections failed", "reason", err, "source", req.Source, "path", req.Path)
+		return nil, err
+	}
+
+	// Names must be unique to select the candidates to import
+	var names []string
+	for _, candidate := range candidates {
+		name := candidate.Connect.Name
+		for i := 2; slices.Contains(names, candidate.Connect.Name); i++ {
+			candidate.Connect.Name = fmt.Sprintf("%s #%d", name, i)
+		}
+		names = append(names, candidate.Connect.Name)
+	}
+	err = app.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketConnections))
+		for _, candidate := range candidates {
+			candidate.Exists = bucket.Get([]byte(candidate.Connect.Name)) != nil
+		}
+		return nil
+	})
+	return candidates, err
+}
+
+// ImportConnections saves the candidates of the preview selected by Names, resolving name
+// conflicts with existing connections like ImportWorkspace
+func (app *App) ImportConnections(req *ConnectionImportRequest) (*WorkspaceImportReport, error) {
+	conflict, err := importConflict(req.Conflict)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := app.PreviewConnectionImport(req)
+	if err != nil {
+		return nil, err
+	}
+	var connects []*ConnectConfig
+	for _, candidate := range candidates {
+		// Candidates without address are only previewed with their warnings
+		if candidate.Connect.Address != "" && (len(req.Names) == 0 || slices.Contains(req.Names, candidate.Connect.Name)) {
+			connects = append(connects, candidate.Connect)
+		}
+	}
+	report := &WorkspaceImportReport{
+		Items:          make([]*WorkspaceImportItem, 0),
+		MissingSecrets: make([]string, 0),
+	}
+	err = app.update(func(tx *bolt.Tx) error {
+		return importConnections(tx, connects, conflict, report)
+	})
+	if err != nil {
+		app.logger.Error("import connections failed", "reason", err, "source", req.Source)
+		return nil, fmt.Errorf("import connections failed: %w", err)
+	}
+	app.logger.Info("import connections", "source", req.Source, "path", req.Path, "count", len(connects))
+	return report, nil
+}
+
+// envCandidates reads the variables of the influx CLI, INFLUX_HOST may be a host, an
+// address or a URL
+func envCandidates(lookup func(string) (string, bool)) []*ConnectionCandidate {
+	var (
+		values = make(map[string]string)
+		found  bool
+	)
+	for _, name := range []string{"INFLUX_HOST", "INFLUX_PORT", "INFLUX_SSL", "INFLUX_UNSAFE_SSL",
+		"INFLUX_USERNAME", "INFLUX_PASSWORD", "INFLUX_DATABASE"} {
+		if value, ok := lookup(name); ok {
+			values[name] = strings.TrimSpace(value)
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	candidate := &ConnectionCandidate{Origin: "environment", Database: values["INFLUX_DATABASE"]}
+
+	host := values["INFLUX_HOST"]
+	if host == "" {
+		host = "localhost"
+	}
+	if !strings.Contains(host, "://") {
+		schema := "http"
+		if ssl, _ := strconv.ParseBool(values["INFLUX_SSL"]); ssl {
+			schema = "https"
+		}
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			port := values["INFLUX_PORT"]
+			if port == "" {
+				port = defaultInfluxPort
+			}
+			host = net.JoinHostPort(host, port)
+		}
+		host = schema + "://" + host
+	}
+	connect, err := connectFromURL(host)
+	if err != nil {
+		candidate.Connect = &ConnectConfig{Name: "influx", HTTPSchema: "http"}
+		candidate.Warnings = append(candidate.Warnings, err.Error())
+		return []*ConnectionCandidate{candidate}
+	}
+	connect.InsecureTls, _ = strconv.ParseBool(values["INFLUX_UNSAFE_SSL"])
+	if values["INFLUX_USERNAME"] != "" {
+		connect.EnableAuth = true
+		connect.Username = values["INFLUX_USERNAME"]
+		connect.Password = values["INFLUX_PASSWORD"]
+	}
+	candidate.Connect = connect
+	return []*ConnectionCandidate{candidate}
+}
+
+// telegrafConfig holds the [[outputs.influxdb]] tables of a Telegraf configuration, the
+// other plugins are ignored
+type telegrafConfig struct {
+	Outputs struct {
+		InfluxDB []*telegrafInfluxDBOutput `toml:"influxdb"`
+	} `toml:"outputs"`
+}
+
+type telegrafInfluxDBOutput struct {
+	URLs               []string `toml:"urls"`
+	URL                string   `toml:"url"`
+	Database           string   `toml:"database"`
+	Username           string   `toml:"username"`
+	Password           string   `toml:"password"`
+	TLSCA              string   `toml:"tls_ca"`
+	TLSCert            string   `toml:"tls_cert"`
+	TLSKey             string   `toml:"tls_key"`
+	InsecureSkipVerify bool     `toml:"insecure_skip_verify"`
+}
+
+type grafanaProvisioning struct {
+	Datasources []*grafanaDatasource `yaml:"datasources"`
+}
+
+type grafanaDatasource struct {
+	Name              string         `yaml:"name"`
+	Type              string         `yaml:"type"`
+	URL               string         `yaml:"url"`
+	User              string         `yaml:"user"`
+	Password          string         `yaml:"password"`
+	Database          string         `yaml:"database"`
+	BasicAuth         bool           `yaml:"basicAuth"`
+	BasicAuthUser     string         `yaml:"basicAuthUser"`
+	BasicAuthPassword string         `yaml:"basicAuthPassword"`
+	JSONData          map[string]any `yaml:"jsonData"`
+	SecureJSONData    map[string]any `yaml:"secureJsonData"`
+}
+
+// grafanaCandidates reads the InfluxDB datasources of a provisioning file, or of the YAML
+// files of a provisioning directory
+func grafanaCandidates(path string) ([]*ConnectionCandidate, error) {
+	if path == "" {
+		return nil, errors.New("provisioning file required")
+	}
+	files := []string{path}
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		files = nil
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, err := filepath.Glob(filepath.Join(path, pattern))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+		}
+		slices.Sort(files)
+	}
+
+	var candidates = make([]*ConnectionCandidate, 0)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var provisioning grafanaProvisioning
+		// Grafana expands $VAR and ${VAR} in provisioning files
+		if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(content))), &provisioning); err != nil {
+			return nil, fmt.Errorf("parse %s failed: %w", file, err)
+		}
+		for _, datasource := range provisioning.Datasources {
+			if datasource == nil || datasource.Type != "influxdb" {
+				continue
+			}
+			candidates = append(candidates, datasource.candidate(filepath.Base(file)))
+		}
+	}
+	return candidates, nil
+}
+
+func (ds *grafanaDatasource) candidate(file string) *ConnectionCandidate {
+	var (
+		jsonData   = func(key string) string { return mapString(ds.JSONData, key) }
+		secureData = func(key string) string { return mapString(ds.SecureJSONData, key) }
+		candidate  = &ConnectionCandidate{Origin: file + ": " + ds.Name, Database: ds.Database}
+	)
+	if dbName := jsonData("dbName"); dbName != "" {
+		candidate.Database = dbName
+	}
+	connect, err := connectFromURL(ds.URL)
+	if err != nil {
+		connect = &ConnectConfig{HTTPSchema: "http"}
+		candidate.Warnings = append(candidate.Warnings, err.Error())
+	}
+	connect.Name = ds.Name
+	candidate.Connect = connect
+
+	switch {
+	case ds.User != "":
+		connect.EnableAuth = true
+		connect.Username = ds.User
+		connect.Password = ds.Password
+		if password := secureData("password"); password != "" {
+			connect.Password = password
+		}
+	case ds.BasicAuth && ds.BasicAuthUser != "":
+		connect.EnableAuth = true
+		connect.Username = ds.BasicAuthUser
+		connect.Password = ds.BasicAuthPassword
+		if password := secureData("basicAuthPassword"); password != "" {
+			connect.Password = password
+		}
+	}
+	if skip, _ := strconv.ParseBool(jsonData("tlsSkipVerify")); skip {
+		connect.InsecureTls = true
+	}
+	if version := jsonData("version"); version != "" && !strings.EqualFold(version, "InfluxQL") {
+		candidate.Warnings = append(candidate.Warnings, fmt.Sprintf("query language %s is not supported, InfluxQL is used", version))
+	}
+	if secureData("token") != "" {
+		candidate.Warnings = append(candidate.Warnings, "token authentication is not supported")
+	}
+	if secureData("tlsCACert") != "" || secureData("tlsClientCert") != "" {
+		candidate.Warnings = append(candidate.Warnings, "TLS certificates are embedded in the datasource, configure the certificate files")
+	}
+	return candidate
+}
+
+// telegrafCandidates reads the [[outputs.influxdb]] sections of a Telegraf configuration,
+// one connection per URL
+func telegrafCandidates(path string) ([]*ConnectionCandidate, error) {
+	if path == "" {
+		return nil, errors.New("telegraf config required")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// Telegraf expands $VAR and ${VAR} in configuration files
+	var config telegrafConfig
+	if _, err := toml.Decode(os.ExpandEnv(string(content)), &config); err != nil {
+		return nil, fmt.Errorf("parse %s failed: %w", path, err)
+	}
+
+	var candidates = make([]*ConnectionCandidate, 0)
+	for i, output := range config.Outputs.InfluxDB {
+		urls := output.URLs
+		if output.URL != "" {
+			urls = append(urls, output.URL)
+		}
+		if len(urls) == 0 {
+			urls = append(urls, "http://localhost:"+defaultInfluxPort)
+		}
+		for _, rawURL := range urls {
+			candidate := &ConnectionCandidate{
+				Origin:   fmt.Sprintf("%s: outputs.influxdb #%d", filepath.Base(path), i+1),
+				Database: cmp.Or(output.Database, "telegraf"),
+			}
+			connect, err := connectFromURL(rawURL)
+			if err != nil {
+				connect = &ConnectConfig{Name: rawURL, HTTPSchema: "http"}
+				candidate.Warnings = append(candidate.Warnings, err.Error())
+			}
+			if output.Username != "" {
+				connect.EnableAuth = true
+				connect.Username = output.Username
+				connect.Password = output.Password
+			}
+			connect.InsecureTls = output.InsecureSkipVerify
+			connect.CACertificate = output.TLSCA
+			connect.ClientCertificate = output.TLSCert
+			connect.ClientKey = output.TLSKey
+			candidate.Connect = connect
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
+
+// connectFromURL converts an InfluxDB URL into a connection named after its address
+func connectFromURL(rawURL string) (*ConnectConfig, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %s: %w", rawURL, err)
+	}
+	schema := strings.ToLower(u.Scheme)
+	if schema != "http" && schema != "https" {
+		return nil, fmt.Errorf("unsupported url scheme: %s", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid url %s: host required", rawURL)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), defaultInfluxPort)
+	}
+	connect := &ConnectConfig{Name: address, Address: address, HTTPSchema: schema}
+	if u.User != nil {
+		connect.EnableAuth = true
+		connect.Username = u.User.Username()
+		connect.Password, _ = u.User.Password()
+	}
+	return connect, nil
+}
+
+// mapString returns the value of key as text, empty when missing
+func mapString(values map[string]any, key string) string {
+	if value, ok := values[key]; ok && value != nil {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
@@ -0,0 +1,373 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+)
+
+func (app *App) ListContinuousQueries(connectName string) ([]*ContinuousQuery, error) {
+	httpClient, err := app.getDialer(connectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", connectName)
+		return nil, err
+	}
+	result, err := querySingle(app.ctx, httpClient, "", "SHOW CONTINUOUS QUERIES")
+	if err != nil {
+		app.logger.Error("show continuous queries failed", "reason", err, "name", connectName)
+		return nil, fmt.Errorf("show continuous queries failed: %w", err)
+	}
+
+	var queries []*ContinuousQuery
+	for _, series := range result.Series {
+		nameIdx, queryIdx := -1, -1
+		for i, col := range series.Columns {
+			if col == "name" {
+				nameIdx = i
+			} else if col == "query" {
+				queryIdx = i
+			}
+		}
+		if nameIdx == -1 || queryIdx == -1 {
+			return nil, errors.New("missing required columns in continuous query response")
+		}
+		for _, row := range series.Values {
+			if len(row) <= nameIdx || len(row) <= queryIdx {
+				continue
+			}
+			name, _ := row[nameIdx].(string)
+			query, _ := row[queryIdx].(string)
+			cq := parseContinuousQuery(query)
+			cq.Name = name
+			cq.Database = series.Name
+			queries = append(queries, cq)
+		}
+	}
+	return queries, nil
+}
+
+func (app *App) CreateContinuousQuery(req *CreateContinuousQueryRequest) error {
+	if req.Database == "" {
+		return errors.New("database required")
+	}
+	if req.Name == "" {
+		return errors.New("continuous query name required")
+	}
+	stmt, err := parseSelectStatement(req.Select)
+	if err != nil {
+		return err
+	}
+	if stmt.Select.Into == nil {
+		return errors.New("continuous query requires an INTO clause")
+	}
+	if groupByTime(stmt.Select) == nil {
+		return errors.New("continuous query requires a GROUP BY time() clause")
+	}
+	httpClient, err := app.getDialer(req.ConnectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", req.ConnectName)
+		return err
+	}
+
+	var command strings.Builder
+	command.WriteString("CREATE CONTINUOUS QUERY " + quoteIdentifier(req.Name) + " ON " + quoteIdentifier(req.Database))
+	for _, value := range []string{req.ResampleEvery, req.ResampleFor} {
+		if value == "" {
+			continue
+		}
+		if _, err := parseInfluxDuration(value); err != nil {
+			return fmt.Errorf("invalid resample interval: %w", err)
+		}
+	}
+	if req.ResampleEvery != "" || req.ResampleFor != "" {
+		command.WriteString(" RESAMPLE")
+		if req.ResampleEvery != "" {
+			command.WriteString(" EVERY " + req.ResampleEvery)
+		}
+		if req.ResampleFor != "" {
+			command.WriteString(" FOR " + req.ResampleFor)
+		}
+	}
+	command.WriteString(" BEGIN " + stmt.Text + " END")
+
+	if err := app.guardBuiltCommand(req.ConnectName, req.Database, command.String()); err != nil {
+		return err
+	}
+	if _, err := querySingle(app.ctx, httpClient, req.Database, command.String()); err != nil {
+		app.logger.Error("create continuous query failed", "reason", err, "name", req.Name, "db", req.Database)
+		return fmt.Errorf("create continuous query failed: %w", err)
+	}
+	app.logger.Info("create continuous query", "name", req.Name, "db", req.Database)
+	return nil
+}
+
+// DropContinuousQuery drops the continuous query. On production connections it returns a
+// confirmation first, the query is dropped when called again with the confirmation token.
+func (app *App) DropContinuousQuery(connectName, database, name, confirmToken string) (*ExecuteConfirmation, error) {
+	command := "DROP CONTINUOUS QUERY " + quoteIdentifier(name) + " ON " + quoteIdentifier(database)
+	confirmation, err := app.guardCommand(connectName, database, confirmToken, command)
+	if err != nil || confirmation != nil {
+		return confirmation, err
+	}
+	httpClient, err := app.getDialer(connectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", connectName)
+		return nil, err
+	}
+	if _, err := querySingle(app.ctx, httpClient, database, command); err != nil {
+		app.logger.Error("drop continuous query failed", "reason", err, "name", name, "db", database)
+		return nil, fmt.Errorf("drop continuous query failed: %w", err)
+	}
+	app.logger.Info("drop continuous query", "name", name, "db", database)
+	return nil, nil
+}
+
+// DryRunContinuousQuery executes the SELECT of a continuous query over the requested
+// window without its INTO clause, so the output can be checked before the CQ is created
+func (app *App) DryRunContinuousQuery(req *DryRunContinuousQueryRequest) (*DryRunContinuousQueryResponse, error) {
+	if req.Start == "" {
+		return nil, errors.New("start time required")
+	}
+	httpClient, err := app.getDialer(req.ConnectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", req.ConnectName)
+		return nil, err
+	}
+	command, err := buildDryRunQuery(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := app.guardBuiltCommand(req.ConnectName, req.Database, command); err != nil {
+		return nil, err
+	}
+
+	var startTime = time.Now()
+	response, err := httpClient.Query(app.ctx, &opengemini.Query{
+		Database:        req.Database,
+		RetentionPolicy: req.RetentionPolicy,
+		Command:         command,
+		Precision:       opengemini.PrecisionRFC3339,
+	})
+	executionTime := time.Since(startTime).Milliseconds()
+	if err != nil {
+		app.logger.Error("dry run continuous query failed", "reason", err, "command", command)
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("dry run continuous query failed: %s", response.Error)
+	}
+
+	var result = &DryRunContinuousQueryResponse{Query: command, ExecutionTime: float64(executionTime)}
+	if len(response.Results) == 0 {
+		return result, nil
+	}
+	if response.Results[0].Error != "" {
+		return nil, fmt.Errorf("dry run continuous query failed: %s", response.Results[0].Error)
+	}
+	for _, series := range response.Results[0].Series {
+		values := make([][]any, 0, len(series.Values))
+		for _, v := range series.Values {
+			values = append(values, v)
+		}
+		result.Series = append(result.Series, &QuerySeries{
+			Name:    series.Name,
+			Tags:    series.Tags,
+			Columns: series.Columns,
+			Values:  values,
+		})
+	}
+	return result, nil
+}
+
+// buildDryRunQuery removes the INTO clause of the SELECT and restricts it to the
+// requested time window
+func buildDryRunQuery(req *DryRunContinuousQueryRequest, now time.Time) (string, error) {
+	start, err := dryRunTimeBound(req.Start, now)
+	if err != nil {
+		return "", fmt.Errorf("invalid start time: %w", err)
+	}
+	end := now
+	if req.End != "" {
+		if end, err = dryRunTimeBound(req.End, now); err != nil {
+			return "", fmt.Errorf("invalid end time: %w", err)
+		}
+	}
+	if !start.Before(end) {
+		return "", errors.New("start time must be before end time")
+	}
+	stmt, err := parseSelectStatement(req.Select)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		selectStmt    = stmt.Select
+		edits         []textEdit
+		timeCondition = fmt.Sprintf("time >= %s AND time < %s",
+			quoteString(start.UTC().Format(time.RFC3339Nano)), quoteString(end.UTC().Format(time.RFC3339Nano)))
+	)
+	if into := selectStmt.Into; into != nil {
+		// From the end of the field list to the end of the target
+		for i, token := range stmt.Tokens {
+			if token.IsKeyword("INTO") && i > 0 && token.End.Offset <= into.Start.Offset {
+				offset := stmt.Tokens[i-1].End.Offset
+				edits = append(edits, textEdit{offset: offset, length: into.EndPos.Offset - offset})
+				break
+			}
+		}
+	}
+	if condition := selectStmt.Condition; condition != nil {
+		conditionStart, conditionEnd := condition.Span()
+		edits = append(edits,
+			textEdit{offset: conditionStart.Offset, text: "("},
+			textEdit{offset: conditionEnd.Offset, text: ") AND " + timeCondition},
+		)
+	} else {
+		edits = append(edits, textEdit{offset: selectStmt.SourcesEnd.Offset, text: " WHERE " + timeCondition})
+	}
+	if req.Limit > 0 && !selectStmt.HasLimit {
+		edits = append(edits, textEdit{offset: limitOffset(stmt), text: fmt.Sprintf(" LIMIT %d", req.Limit)})
+	}
+	// Comments and the ';' around the statement are left out
+	query := applyTextEdits(req.Select[:stmt.End.Offset], edits)[stmt.Start.Offset:]
+	return strings.TrimSpace(query), nil
+}
+
+// limitOffset returns where a LIMIT clause goes in a SELECT statement without one: before
+// its OFFSET, SLIMIT, SOFFSET and TZ() clauses, if any
+func limitOffset(stmt *Statement) int {
+	tokens := stmt.Tokens
+	for i, token := range tokens {
+		// Subqueries are part of the FROM clause, the tokens after it belong to the statement
+		if token.Pos.Offset < stmt.Select.SourcesEnd.Offset {
+			continue
+		}
+		switch {
+		case token.IsKeyword("OFFSET"), token.IsKeyword("SLIMIT"), token.IsKeyword("SOFFSET"),
+			token.Type == TokenIdent && strings.EqualFold(token.Text, "tz") && i+1 < len(tokens) && tokens[i+1].Type == TokenLParen:
+			return tokens[i-1].End.Offset
+		}
+	}
+	return stmt.End.Offset
+}
+
+// parseSelectStatement parses text holding a single SELECT statement, such as the SELECT
+// of a continuous query or stream. Anything else is refused.
+func parseSelectStatement(text string) (*Statement, error) {
+	parsed := ParseInfluxQL(text)
+	if err := parsed.Err(); err != nil {
+		return nil, err
+	}
+	if len(parsed.Statements) != 1 {
+		return nil, fmt.Errorf("a single SELECT statement required, got %d statements", len(parsed.Statements))
+	}
+	stmt := parsed.Statements[0]
+	if stmt.Kind != "SELECT" {
+		return nil, fmt.Errorf("SELECT statement required, got %s", stmt.Kind)
+	}
+	return stmt, nil
+}
+
+// groupByTime returns the time() dimension of the SELECT, nil when it has none
+func groupByTime(stmt *SelectStatement) *Call {
+	for _, dimension := range stmt.Dimensions {
+		if call, ok := dimension.(*Call); ok && call.Name == "time" {
+			return call
+		}
+	}
+	return nil
+}
+
+// parseContinuousQuery extracts the interesting parts of a CREATE CONTINUOUS QUERY statement
+func parseContinuousQuery(query string) *ContinuousQuery {
+	var cq = &ContinuousQuery{Query: query}
+	parsed := ParseInfluxQL(query)
+	if len(parsed.Statements) != 1 {
+		return cq
+	}
+	var (
+		tokens     = parsed.Statements[0].Tokens
+		begin, end = -1, -1
+	)
+	for i, token := range tokens {
+		switch {
+		case token.IsKeyword("BEGIN") && begin == -1:
+			begin = i
+		case token.IsKeyword("END"):
+			end = i
+		case begin == -1 && i+1 < len(tokens) && (token.IsKeyword("EVERY") || token.IsKeyword("FOR")):
+			// RESAMPLE EVERY <interval> FOR <interval>
+			if token.Value == "EVERY" {
+				cq.ResampleEvery = tokens[i+1].Text
+			} else {
+				cq.ResampleFor = tokens[i+1].Text
+			}
+		}
+	}
+	if begin == -1 || end <= begin+1 {
+		return cq
+	}
+	cq.Select = query[tokens[begin+1].Pos.Offset:tokens[end-1].End.Offset]
+	cq.Target, cq.Source, cq.Interval, cq.Offset = parseSelectParts(cq.Select)
+	return cq
+}
+
+// parseSelectParts returns the INTO target, FROM source and GROUP BY time() interval and
+// offset of a SELECT statement, leaving parts that are absent empty
+func parseSelectParts(text string) (target, source, interval, offset string) {
+	parsed := ParseInfluxQL(text)
+	if len(parsed.Statements) != 1 || parsed.Statements[0].Select == nil {
+		return "", "", "", ""
+	}
+	// Clauses parsed before an error are still reported
+	stmt := parsed.Statements[0].Select
+	if stmt.Into != nil {
+		target = spanText(text, stmt.Into)
+	}
+	if len(stmt.Sources) > 0 {
+		start, _ := stmt.Sources[0].Span()
+		source = text[start.Offset:stmt.SourcesEnd.Offset]
+	}
+	if call := groupByTime(stmt); call != nil {
+		if len(call.Args) > 0 {
+			interval = spanText(text, call.Args[0])
+		}
+		if len(call.Args) > 1 {
+			offset = spanText(text, call.Args[1])
+		}
+	}
+	return target, source, interval, offset
+}
+
+// spanText returns the source text of a node parsed from text
+func spanText(text string, node interface{ Span() (Position, Position) }) string {
+	start, end := node.Span()
+	return text[start.Offset:end.Offset]
+}
+
+// dryRunTimeBound reads a bound of the dry run window, a time string may be quoted as in
+// InfluxQL. Relative bounds are resolved against now.
+func dryRunTimeBound(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		value = value[1 : len(value)-1]
+	}
+	return parseTimeBound(value, now, time.UTC)
+}
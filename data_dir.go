@@ -0,0 +1,256 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	DataDirSourceFlag     = "flag"
+	DataDirSourceEnv      = "env"
+	DataDirSourcePortable = "portable"
+	DataDirSourceSettings = "settings"
+	DataDirSourceDefault  = "default"
+
+	dataDirFlag = "--data-dir"
+	dataDirEnv  = "OPENGEMINI_STUDIO_DATA_DIR"
+	// portableMarker next to the binary keeps the data in the data directory beside it
+	portableMarker = "portable"
+	// dataDirPointer in the default directory records the directory the data was moved to
+	dataDirPointer = "data_dir"
+)
+
+var (
+	defaultWorkDirectory = workDirectory
+	dataDirectorySource  = DataDirSourceDefault
+)
+
+// resolveWorkDirectory chooses the data directory: the --data-dir flag, the environment
+// variable, portable mode, the directory set in the settings, then the home directory
+func resolveWorkDirectory(args []string, getenv func(string) string) (string, string) {
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, dataDirFlag+"="); ok && value != "" {
+			return absPath(value), DataDirSourceFlag
+		}
+		if arg == dataDirFlag && i+1 < len(args) {
+			return absPath(args[i+1]), DataDirSourceFlag
+		}
+	}
+	if value := getenv(dataDirEnv); value != "" {
+		return absPath(value), DataDirSourceEnv
+	}
+	if executable, err := os.Executable(); err == nil {
+		dir := filepath.Dir(executable)
+		if _, err := os.Stat(filepath.Join(dir, portableMarker)); err == nil {
+			return filepath.Join(dir, "data"), DataDirSourcePortable
+		}
+	}
+	if content, err := os.ReadFile(filepath.Join(defaultWorkDirectory, dataDirPointer)); err == nil {
+		if value := strings.TrimSpace(string(content)); value != "" {
+			return value, DataDirSourceSettings
+		}
+	}
+	return defaultWorkDirectory, DataDirSourceDefault
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+func (app *App) GetDataDirectory() *DataDirectoryInfo {
+	app.storeMu.RLock()
+	defer app.storeMu.RUnlock()
+	return &DataDirectoryInfo{
+		Path:        workDirectory,
+		Source:      dataDirectorySource,
+		Relocatable: dataDirectorySource == DataDirSourceSettings || dataDirectorySource == DataDirSourceDefault,
+	}
+}
+
+// RelocateDataDirectory moves the files of the data directory to target and reopens the
+// config store and log there. Files are copied before the originals are removed, the old
+// directory stays in use when anything fails. Other callers of the store wait until the
+// move is over.
+func (app *App) RelocateDataDirectory(target string) error {
+	if target = strings.TrimSpace(target); target == "" {
+		return errors.New("data directory required")
+	}
+	target = absPath(target)
+
+	app.storeMu.Lock()
+	defer app.storeMu.Unlock()
+	if dataDirectorySource != DataDirSourceSettings && dataDirectorySource != DataDirSourceDefault {
+		return fmt.Errorf("data directory is set by %s and cannot be relocated", dataDirectorySource)
+	}
+	source, sourceKind := workDirectory, dataDirectorySource
+	if target == source {
+		return nil
+	}
+	if rel, err := filepath.Rel(source, target); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errors.New("data directory cannot be moved into itself")
+	}
+	if _, err := os.Stat(filepath.Join(target, "config.db")); err == nil {
+		return fmt.Errorf("%s already holds a config store", target)
+	}
+	if err := os.MkdirAll(target, 0750); err != nil {
+		return fmt.Errorf("create data directory failed: %w", err)
+	}
+
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, entry := range entries {
+		// The pointer stays in the default directory to find the data again
+		if source == defaultWorkDirectory && entry.Name() == dataDirPointer {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+
+	app.logger.Info("relocate data directory", "from", source, "to", target)
+	if err := app.db.Close(); err != nil {
+		return fmt.Errorf("close config store failed: %w", err)
+	}
+	app.logger.Close()
+
+	var copied []string
+	removeCopied := func() {
+		for _, name := range copied {
+			_ = os.RemoveAll(filepath.Join(target, name))
+		}
+	}
+	err = func() error {
+		for _, name := range names {
+			if err := copyPath(filepath.Join(source, name), filepath.Join(target, name)); err != nil {
+				return err
+			}
+			copied = append(copied, name)
+		}
+		return writeDataDirPointer(target)
+	}()
+	if err == nil {
+		workDirectory = target
+		dataDirectorySource = DataDirSourceSettings
+		if target == defaultWorkDirectory {
+			dataDirectorySource = DataDirSourceDefault
+		}
+	} else {
+		removeCopied()
+	}
+
+	logErr := app.logger.Reopen()
+	db, openErr := ConnectDatabase(app.logger)
+	if openErr != nil && err == nil {
+		// Go back to the old directory, its files are still in place
+		app.logger.Error("open relocated config store failed", "reason", openErr, "path", target)
+		err = openErr
+		workDirectory, dataDirectorySource = source, sourceKind
+		if pointerErr := writeDataDirPointer(source); pointerErr != nil {
+			app.logger.Error("restore data directory pointer failed", "reason", pointerErr)
+		}
+		removeCopied()
+		logErr = app.logger.Reopen()
+		db, openErr = ConnectDatabase(app.logger)
+	}
+	if logErr != nil {
+		app.logger.Error("open log file failed, log to stdout only", "reason", logErr)
+	}
+	if openErr != nil {
+		return fmt.Errorf("open config store failed: %w", openErr)
+	}
+	app.db = db
+	if err != nil {
+		app.logger.Error("relocate data directory failed", "reason", err, "to", target)
+		return fmt.Errorf("relocate data directory failed: %w", err)
+	}
+
+	for _, name := range names {
+		if err := os.RemoveAll(filepath.Join(source, name)); err != nil {
+			app.logger.Warn("remove relocated file failed", "reason", err, "path", filepath.Join(source, name))
+		}
+	}
+	app.logger.Info("data directory relocated", "path", target)
+	return nil
+}
+
+// writeDataDirPointer records dir in the default directory, the default needs no pointer
+func writeDataDirPointer(dir string) error {
+	path := filepath.Join(defaultWorkDirectory, dataDirPointer)
+	if dir == defaultWorkDirectory {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(defaultWorkDirectory, 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(dir+"\n"), 0600)
+}
+
+// copyPath copies the file or directory tree src to dst
+func copyPath(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
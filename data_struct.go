@@ -16,6 +16,7 @@ package main
 
 import (
 	"encoding/json"
+	"log/slog"
 )
 
 type ConnectConfig struct {
@@ -38,14 +39,16 @@ type ConnectConfig struct {
 	SSHPassword      string `json:"ssh_password"`
 	SSHKeyPath       string `json:"ssh_key_path"`
 	SSHKeyPassphrase string `json:"ssh_key_passphrase"`
-	debug            bool   `json:"-"`
+	// Safe mode
+	ReadOnly bool   `json:"read_only"` // Refuse writes, DDL and DCL
+	Label    string `json:"label"`     // Environment label, destructive statements need confirmation on production
+	debug    bool   `json:"-"`
 }
 
 var defaultAppSetting = &AppSetting{
 	Language:        "en",
 	ThemeMode:       "light",
-	MaxHistoryCount: 100,
-	DataDirectory:   "./data",
+	MaxHistoryCount: 1000,
 	Debug:           false,
 }
 
@@ -54,8 +57,25 @@ type AppSetting struct {
 	ThemeMode       string `json:"theme_mode"`
 	CustomFont      string `json:"custom_font"`
 	MaxHistoryCount int    `json:"max_history_count"`
-	DataDirectory   string `json:"data_dir"`
+	DataDirectory   string `json:"data_dir"` // Directory in use, changing it relocates the data
 	Debug           bool   `json:"debug"`
+
+	ResultFormat *ResultFormat `json:"result_format"` // Default for ExecuteRequest.Format
+	Log          *LogSetting   `json:"log"`
+}
+
+type LogSetting struct {
+	Level      string `json:"level"`       // debug, info (default), warn or error; Debug forces debug
+	Format     string `json:"format"`      // text (default) or json
+	MaxSize    int    `json:"max_size"`    // Megabytes before app.log is rotated, 10 by default
+	MaxAge     int    `json:"max_age"`     // Days before app.log is rotated and rotated files are removed, 7 by default
+	MaxBackups int    `json:"max_backups"` // Rotated files kept, 5 by default
+}
+
+type ResultFormat struct {
+	Timezone   string `json:"timezone"`    // IANA name, a tz() clause of the query takes precedence, UTC when both are empty
+	TimeFormat string `json:"time_format"` // rfc3339, human or epoch
+	EpochUnit  string `json:"epoch_unit"`  // ns, us, ms or s when TimeFormat is epoch
 }
 
 func (as *AppSetting) Marshal() []byte {
@@ -71,9 +91,17 @@ type RetentionPolicy struct {
 	Duration string `json:"duration"`
 }
 
+type FieldKey struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // float, integer, string or boolean
+}
+
 type DatabaseMetadata struct {
-	RetentionPolicy []*RetentionPolicy `json:"retention_policies"`
-	Measurements    []string           `json:"measurements"`
+	RetentionPolicy []*RetentionPolicy  `json:"retention_policies"`
+	Measurements    []string            `json:"measurements"`
+	MeasurementInfo []*MeasurementInfo  `json:"measurement_info"`
+	Downsamples     []*DownsamplePolicy `json:"downsamples"`
+	Streams         []*StreamTask       `json:"streams"`
 }
 
 type ExecuteRequest struct {
@@ -81,8 +109,16 @@ type ExecuteRequest struct {
 	Database        string `json:"database"`
 	RetentionPolicy string `json:"retention_policy"`
 	Measurement     string `json:"measurement"`
-	Precision       string `json:"precision"`
+	Precision       string `json:"precision"` // Write precision, and the epoch of results when no Format applies
 	Command         string `json:"command"`
+	ConfirmToken    string `json:"confirm_token"` // Token of the confirmation returned for this command
+	// IgnoreSyntaxErrors sends statements the parser reports errors for as written, for
+	// syntax it does not know. They still go through the safe mode of the connection.
+	IgnoreSyntaxErrors bool `json:"ignore_syntax_errors"`
+
+	Variables []*QueryVariable `json:"variables"`  // Values of the $name variables in Command
+	TimeRange *TimeRange       `json:"time_range"` // Added to SELECTs without time condition, and used by $__timeFilter
+	Format    *ResultFormat    `json:"format"`     // Rendering of the time column, the setting default when nil
 }
 
 func (e *ExecuteRequest) String() string {
@@ -99,6 +135,18 @@ type ExecuteResponse struct {
 	ExecutionTime float64  `json:"execution_time"` // Execution time in milliseconds
 	Columns       []string `json:"columns"`
 	Values        [][]any  `json:"values"`
+	Timezone      string   `json:"timezone"`    // Timezone the time column was rendered in, empty when returned as is
+	TimeFormat    string   `json:"time_format"` // Format of the time column, empty when returned as is
+	// Set instead of executing when destructive statements need to be confirmed
+	Confirmation *ExecuteConfirmation `json:"confirmation"`
+}
+
+type ExecuteConfirmation struct {
+	Token       string       `json:"token"`
+	ConnectName string       `json:"connect_name"`
+	Label       string       `json:"label"`
+	Statements  []*Statement `json:"statements"` // Destructive statements of the command and those with syntax errors
+	ExpiresAt   int64        `json:"expires_at"` // Unix milliseconds
 }
 
 type History struct {
@@ -110,6 +158,60 @@ type History struct {
 	RetentionPolicy string  `json:"retention_policy"`
 	Success         bool    `json:"success"`
 	Error           string  `json:"error"`
+
+	ConnectName string          `json:"connect_name"`
+	Stats       *ExecutionStats `json:"stats"` // Nil for records saved before statistics were collected
+}
+
+type ExecutionStats struct {
+	TimeToFirstByte float64 `json:"time_to_first_byte"` // Milliseconds until the first response headers, summed over statements
+	ResponseBytes   int64   `json:"response_bytes"`
+	Statements      int     `json:"statements"` // Statements executed, including the failed one
+	Series          int     `json:"series"`
+	Rows            int64   `json:"rows"`
+	ServerVersion   string  `json:"server_version"` // Reported by the server in the response headers
+}
+
+type QueryProfile struct {
+	TimeToFirstByte float64 `json:"time_to_first_byte"` // Milliseconds
+	ResponseBytes   int64   `json:"response_bytes"`
+	ServerVersion   string  `json:"server_version"`
+	RequestID       string  `json:"request_id"`
+}
+
+type QueryStatsRequest struct {
+	ConnectName string `json:"connect_name"` // Optional
+	Since       int64  `json:"since"`        // Unix milliseconds, optional
+	Until       int64  `json:"until"`        // Unix milliseconds, optional
+	Limit       int    `json:"limit"`        // Number of slowest queries and shapes, 10 when 0
+}
+
+type QueryShapeStats struct {
+	Shape       string  `json:"shape"` // Query with literals replaced by ?
+	Count       int     `json:"count"`
+	Failures    int     `json:"failures"`
+	P50         float64 `json:"p50"` // Execution time percentiles in milliseconds
+	P95         float64 `json:"p95"`
+	Max         float64 `json:"max"`
+	AverageRows float64 `json:"average_rows"`
+	LastSeen    int64   `json:"last_seen"`
+}
+
+type ConnectionStats struct {
+	ConnectName string  `json:"connect_name"` // Empty for records saved before the connection was recorded
+	Count       int     `json:"count"`
+	Failures    int     `json:"failures"`
+	FailureRate float64 `json:"failure_rate"` // Between 0 and 1
+	P50         float64 `json:"p50"`
+	P95         float64 `json:"p95"`
+}
+
+type QueryStatsReport struct {
+	Total       int                `json:"total"`
+	Failures    int                `json:"failures"`
+	Slowest     []*History         `json:"slowest"`
+	Shapes      []*QueryShapeStats `json:"shapes"` // Sorted by p95 descending
+	Connections []*ConnectionStats `json:"connections"`
 }
 
 func (h *History) Marshal() []byte {
@@ -119,3 +221,431 @@ func (h *History) Marshal() []byte {
 	}
 	return data
 }
+
+type ServerStatsRequest struct {
+	ConnectName string   `json:"connect_name"`
+	Modules     []string `json:"modules"`    // Restrict SHOW STATS to these modules, empty means all
+	Delta       bool     `json:"delta"`      // Sample twice and report per-second rates
+	Interval    int      `json:"interval"`   // Delta sampling interval in milliseconds
+	DebugVars   bool     `json:"debug_vars"` // Also scrape /debug/vars
+}
+
+type StatsEntry struct {
+	Key   string  `json:"key"`
+	Value any     `json:"value"`
+	Type  string  `json:"type"`  // number, string, bool or null
+	Delta float64 `json:"delta"` // Change between the two samples, delta mode only
+	Rate  float64 `json:"rate"`  // Per-second change rate, delta mode only
+}
+
+type StatsGroup struct {
+	Module  string            `json:"module"`
+	Tags    map[string]string `json:"tags"`
+	Entries []*StatsEntry     `json:"entries"`
+}
+
+type ServerStatsResponse struct {
+	Stats     []*StatsGroup `json:"stats"`
+	DebugVars []*StatsGroup `json:"debug_vars"`
+	Delta     bool          `json:"delta"`
+	Interval  float64       `json:"interval"`   // Actual delta sampling interval in milliseconds
+	SampledAt int64         `json:"sampled_at"` // Unix timestamp in milliseconds
+}
+
+type QuerySeries struct {
+	Name    string            `json:"name"`
+	Tags    map[string]string `json:"tags"`
+	Columns []string          `json:"columns"`
+	Values  [][]any           `json:"values"`
+}
+
+type ContinuousQuery struct {
+	Name          string `json:"name"`
+	Database      string `json:"database"`
+	Query         string `json:"query"`          // Full CREATE CONTINUOUS QUERY statement
+	Select        string `json:"select"`         // SELECT statement between BEGIN and END
+	Source        string `json:"source"`         // Measurement in the FROM clause
+	Target        string `json:"target"`         // Measurement in the INTO clause
+	Interval      string `json:"interval"`       // GROUP BY time() interval
+	Offset        string `json:"offset"`         // GROUP BY time() offset
+	ResampleEvery string `json:"resample_every"` // RESAMPLE EVERY duration
+	ResampleFor   string `json:"resample_for"`   // RESAMPLE FOR duration
+}
+
+type CreateContinuousQueryRequest struct {
+	ConnectName   string `json:"connect_name"`
+	Database      string `json:"database"`
+	Name          string `json:"name"`
+	Select        string `json:"select"`
+	ResampleEvery string `json:"resample_every"`
+	ResampleFor   string `json:"resample_for"`
+}
+
+type DryRunContinuousQueryRequest struct {
+	ConnectName     string `json:"connect_name"`
+	Database        string `json:"database"`
+	RetentionPolicy string `json:"retention_policy"`
+	Select          string `json:"select"`
+	Start           string `json:"start"` // now()-1h, RFC3339 (optionally quoted), Unix milliseconds or a UTC date time
+	End             string `json:"end"`   // Same forms as Start, defaults to now()
+	Limit           int    `json:"limit"` // Maximum points per series, 0 means no limit
+}
+
+type DryRunContinuousQueryResponse struct {
+	Query         string         `json:"query"` // Statement that was actually executed
+	Series        []*QuerySeries `json:"series"`
+	ExecutionTime float64        `json:"execution_time"` // Execution time in milliseconds
+}
+
+type DownsampleOperator struct {
+	DataType  string   `json:"data_type"` // float, integer, ...
+	Functions []string `json:"functions"` // Aggregations applied to fields of DataType
+}
+
+type DownsamplePolicy struct {
+	Database        string                `json:"database"`
+	RetentionPolicy string                `json:"retention_policy"`
+	Operators       []*DownsampleOperator `json:"operators"`
+	Duration        string                `json:"duration"`
+	SampleIntervals []string              `json:"sample_intervals"`
+	TimeIntervals   []string              `json:"time_intervals"`
+}
+
+type CreateDownsampleRequest struct {
+	ConnectName     string                `json:"connect_name"`
+	Database        string                `json:"database"`
+	RetentionPolicy string                `json:"retention_policy"`
+	Operators       []*DownsampleOperator `json:"operators"`
+	Duration        string                `json:"duration"`
+	SampleIntervals []string              `json:"sample_intervals"`
+	TimeIntervals   []string              `json:"time_intervals"`
+}
+
+type StreamTask struct {
+	Name     string `json:"name"`
+	Database string `json:"database"`
+	Query    string `json:"query"`    // Full CREATE STREAM statement
+	Select   string `json:"select"`   // SELECT statement after ON
+	Source   string `json:"source"`   // Measurement in the FROM clause
+	Target   string `json:"target"`   // Measurement in the INTO clause
+	Interval string `json:"interval"` // GROUP BY time() interval
+	Delay    string `json:"delay"`
+}
+
+type CreateStreamRequest struct {
+	ConnectName string `json:"connect_name"`
+	Database    string `json:"database"`
+	Name        string `json:"name"`
+	Target      string `json:"target"` // Optional db.rp.measurement to write into
+	Select      string `json:"select"`
+	Delay       string `json:"delay"`
+}
+
+type MeasurementInfo struct {
+	Name        string   `json:"name"`
+	EngineType  string   `json:"engine_type"` // tsstore or columnstore, empty when unknown
+	PrimaryKeys []string `json:"primary_keys"`
+	SortKeys    []string `json:"sort_keys"`
+	ShardKeys   []string `json:"shard_keys"`
+}
+
+type ColumnDefinition struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // tag, int64, float64, string or bool
+}
+
+type CreateMeasurementRequest struct {
+	ConnectName     string              `json:"connect_name"`
+	Database        string              `json:"database"`
+	RetentionPolicy string              `json:"retention_policy"`
+	Name            string              `json:"name"`
+	EngineType      string              `json:"engine_type"` // tsstore or columnstore
+	Columns         []*ColumnDefinition `json:"columns"`
+	PrimaryKeys     []string            `json:"primary_keys"`
+	SortKeys        []string            `json:"sort_keys"`
+	ShardKeys       []string            `json:"shard_keys"`
+	ShardType       string              `json:"shard_type"` // hash or range
+}
+
+type SubscriptionDestination struct {
+	URL    string `json:"url"`
+	Scheme string `json:"scheme"` // http, https or udp
+	Host   string `json:"host"`
+}
+
+type Subscription struct {
+	Database        string                     `json:"database"`
+	RetentionPolicy string                     `json:"retention_policy"`
+	Name            string                     `json:"name"`
+	Mode            string                     `json:"mode"` // ALL or ANY
+	Destinations    []*SubscriptionDestination `json:"destinations"`
+}
+
+type CreateSubscriptionRequest struct {
+	ConnectName     string   `json:"connect_name"`
+	Database        string   `json:"database"`
+	RetentionPolicy string   `json:"retention_policy"`
+	Name            string   `json:"name"`
+	Mode            string   `json:"mode"` // ALL or ANY
+	Destinations    []string `json:"destinations"`
+}
+
+type CompletionRequest struct {
+	ConnectName     string `json:"connect_name"`
+	Database        string `json:"database"`
+	RetentionPolicy string `json:"retention_policy"`
+	Text            string `json:"text"`
+	Cursor          int    `json:"cursor"` // Cursor position as a character offset into Text
+}
+
+type CompletionItem struct {
+	Label      string `json:"label"`
+	Kind       string `json:"kind"`   // keyword, function, database, retention_policy, measurement, tag_key, field_key or tag_value
+	Detail     string `json:"detail"` // e.g. the field type
+	InsertText string `json:"insert_text"`
+}
+
+type CompletionResponse struct {
+	Context      string            `json:"context"` // Detected cursor context, e.g. measurement or tag_value
+	Items        []*CompletionItem `json:"items"`
+	ReplaceStart int               `json:"replace_start"` // Character offsets of the text replaced by an item
+	ReplaceEnd   int               `json:"replace_end"`
+}
+
+type LintRequest struct {
+	ConnectName string `json:"connect_name"` // Optional, enables the schema based rules
+	Database    string `json:"database"`
+	Text        string `json:"text"`
+}
+
+type LintWarning struct {
+	Rule     string   `json:"rule"`
+	Severity string   `json:"severity"` // error or warning
+	Message  string   `json:"message"`
+	Start    Position `json:"start"`
+	End      Position `json:"end"`
+}
+
+type QueryVariable struct {
+	Name    string `json:"name"` // Without the leading $
+	Type    string `json:"type"` // string, identifier, number, duration or time, string when empty
+	Value   string `json:"value"`
+	Default string `json:"default"` // Used when Value is empty
+}
+
+type TimeRange struct {
+	From          string `json:"from"`     // RFC3339, Unix milliseconds, local time or relative to now such as now()-6h
+	To            string `json:"to"`       // Same formats as From, now when empty
+	Timezone      string `json:"timezone"` // IANA name for local times, UTC when empty
+	MaxDataPoints int    `json:"max_data_points"`
+}
+
+type VariableValuesRequest struct {
+	ConnectName string `json:"connect_name"`
+	Database    string `json:"database"`
+	Measurement string `json:"measurement"` // Optional
+	TagKey      string `json:"tag_key"`
+}
+
+type QueryPlan struct {
+	Statement string    `json:"statement"` // The EXPLAIN statement sent to the server
+	Analyze   bool      `json:"analyze"`
+	TotalTime float64   `json:"total_time"` // Milliseconds, 0 without ANALYZE
+	Root      *PlanNode `json:"root"`
+	Raw       []string  `json:"raw"` // Plan text as returned by the server, one entry per line
+}
+
+type PlanNode struct {
+	Operator   string           `json:"operator"`
+	Detail     string           `json:"detail"` // Arguments of the operator
+	Attributes []*PlanAttribute `json:"attributes"`
+	TimeMs     float64          `json:"time_ms"`
+	SelfTimeMs float64          `json:"self_time_ms"` // Time not spent in children
+	Percent    float64          `json:"percent"`      // Share of the total time
+	Rows       int64            `json:"rows"`
+	Cost       float64          `json:"cost"`
+	Hot        bool             `json:"hot"`
+	Children   []*PlanNode      `json:"children"`
+}
+
+type PlanAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type HistoryFilter struct {
+	Text        string `json:"text"` // Words that must all occur in the query or error, case-insensitive
+	ConnectName string `json:"connect_name"`
+	Database    string `json:"database"`
+	Success     *bool  `json:"success"` // Nil for both
+	Since       int64  `json:"since"`   // Unix milliseconds, optional
+	Until       int64  `json:"until"`   // Unix milliseconds, optional
+	Cursor      string `json:"cursor"`  // NextCursor of the previous page
+	Limit       int    `json:"limit"`
+}
+
+type HistoryPage struct {
+	Items      []*History `json:"items"`
+	NextCursor string     `json:"next_cursor"` // Empty on the last page
+}
+
+type SavedQuery struct {
+	ID              string           `json:"id"`
+	Name            string           `json:"name"`
+	Description     string           `json:"description"`
+	Folder          string           `json:"folder"` // Slash separated path, empty for the root
+	Tags            []string         `json:"tags"`
+	Query           string           `json:"query"`
+	ConnectName     string           `json:"connect_name"` // Defaults used when the query is opened
+	Database        string           `json:"database"`
+	RetentionPolicy string           `json:"retention_policy"`
+	Variables       []*QueryVariable `json:"variables"`
+	CreatedAt       int64            `json:"created_at"` // Unix milliseconds
+	UpdatedAt       int64            `json:"updated_at"`
+}
+
+type SavedQueryFilter struct {
+	Text   string   `json:"text"`   // Words that must all occur in the name, description or query
+	Folder string   `json:"folder"` // Includes subfolders, empty for all
+	Tags   []string `json:"tags"`   // Saved queries must have all of them
+}
+
+type WorkspaceExportOptions struct {
+	Format         string `json:"format"`          // json or zip, taken from the file extension when empty
+	Secrets        string `json:"secrets"`         // strip (default) or encrypt
+	Password       string `json:"password"`        // Encrypts the secrets when Secrets is encrypt
+	IncludeHistory bool   `json:"include_history"` // Histories are left out by default
+}
+
+type WorkspaceImportOptions struct {
+	Password string `json:"password"` // Decrypts the secrets of an encrypted bundle
+	Conflict string `json:"conflict"` // skip (default), rename or overwrite
+}
+
+// WorkspaceBundle is the portable file written by ExportWorkspace
+type WorkspaceBundle struct {
+	Version      int                  `json:"version"`
+	ExportedAt   int64                `json:"exported_at"` // Unix milliseconds
+	Secrets      string               `json:"secrets"`     // strip or encrypt
+	Encryption   *WorkspaceEncryption `json:"encryption,omitempty"`
+	Connections  []*ConnectConfig     `json:"connections"`
+	Settings     *AppSetting          `json:"settings"`
+	SavedQueries []*SavedQuery        `json:"saved_queries"`
+	Histories    []*History           `json:"histories"`
+}
+
+type WorkspaceEncryption struct {
+	Algorithm string `json:"algorithm"`
+	Salt      string `json:"salt"` // Base64
+	Time      uint32 `json:"time"` // Argon2id parameters
+	Memory    uint32 `json:"memory"`
+	Threads   uint8  `json:"threads"`
+}
+
+type WorkspaceImportItem struct {
+	Kind    string `json:"kind"`     // connection, saved_query or settings
+	Name    string `json:"name"`     // Name in the bundle
+	Action  string `json:"action"`   // created, skipped, renamed or overwritten
+	NewName string `json:"new_name"` // Set when renamed
+}
+
+type WorkspaceImportReport struct {
+	Version        int                    `json:"version"`
+	Items          []*WorkspaceImportItem `json:"items"`
+	Histories      int                    `json:"histories"`       // Number of history records added
+	MissingSecrets []string               `json:"missing_secrets"` // Connections to complete with their credentials
+}
+
+type ConnectionImportRequest struct {
+	Source   string   `json:"source"`   // env, grafana or telegraf
+	Path     string   `json:"path"`     // Grafana provisioning file or directory, or Telegraf config; unused for env
+	Names    []string `json:"names"`    // Candidates to import, all when empty
+	Conflict string   `json:"conflict"` // skip (default), rename or overwrite
+}
+
+type ConnectionCandidate struct {
+	Connect  *ConnectConfig `json:"connect"`
+	Origin   string         `json:"origin"`   // File or variables the connection was read from
+	Database string         `json:"database"` // Database configured at the origin, for information
+	Exists   bool           `json:"exists"`   // A connection with the name already exists
+	Warnings []string       `json:"warnings"` // Settings that could not be carried over
+}
+
+type DataDirectoryInfo struct {
+	Path        string `json:"path"`
+	Source      string `json:"source"`      // flag, env, portable, settings or default
+	Relocatable bool   `json:"relocatable"` // False when pinned by the flag, the environment or portable mode
+}
+
+type BackupInfo struct {
+	Name      string `json:"name"`
+	Reason    string `json:"reason"` // auto, migration or restore
+	Size      int64  `json:"size"`
+	CreatedAt int64  `json:"created_at"` // Unix milliseconds
+}
+
+type RecoveryReport struct {
+	CorruptedFile string            `json:"corrupted_file"` // The damaged store, kept for inspection
+	Buckets       []*SalvagedBucket `json:"buckets"`
+	Backup        string            `json:"backup"` // Backup used when the damaged store was unreadable
+	Error         string            `json:"error"`  // Why the damaged store was unreadable
+}
+
+type SalvagedBucket struct {
+	Name      string `json:"name"`
+	Recovered int    `json:"recovered"` // Records copied to the new store
+	Complete  bool   `json:"complete"`  // False when records after an unreadable page were lost
+	Error     string `json:"error"`
+}
+
+type LogFilter struct {
+	AfterSeq int64  `json:"after_seq"` // Only entries after this sequence, the LastSeq of the previous page
+	Level    string `json:"level"`     // Minimum level, info by default
+	Text     string `json:"text"`      // Words that must all occur in the message or attributes
+	Limit    int    `json:"limit"`     // Newest entries returned at most, 200 by default
+}
+
+type LogEntry struct {
+	Seq     int64             `json:"seq"`
+	Time    int64             `json:"time"` // Unix milliseconds
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Source  string            `json:"source"`
+	Attrs   map[string]string `json:"attrs"`
+	level   slog.Level        `json:"-"`
+}
+
+type LogPage struct {
+	Entries []*LogEntry `json:"entries"`
+	LastSeq int64       `json:"last_seq"`
+}
+
+type RequestTrace struct {
+	Seq             int64             `json:"seq"`
+	Time            int64             `json:"time"` // Unix milliseconds of the start of the request
+	Connection      string            `json:"connection"`
+	Method          string            `json:"method"`
+	URL             string            `json:"url"` // Credentials in the query are redacted
+	RequestHeaders  map[string]string `json:"request_headers"`
+	RequestBody     string            `json:"request_body"` // Redacted, truncated to 4 KB
+	RequestBytes    int64             `json:"request_bytes"`
+	Status          int               `json:"status"` // 0 when no response was received
+	ResponseHeaders map[string]string `json:"response_headers"`
+	ResponseBody    string            `json:"response_body"` // Truncated to 4 KB
+	ResponseBytes   int64             `json:"response_bytes"`
+	TimeToFirstByte float64           `json:"time_to_first_byte"` // Milliseconds until the response headers
+	Duration        float64           `json:"duration"`           // Milliseconds until the response body was closed
+	Error           string            `json:"error"`
+}
+
+type RequestTraceFilter struct {
+	AfterSeq   int64  `json:"after_seq"` // Only traces after this sequence, the LastSeq of the previous page
+	Connection string `json:"connection"`
+	FailedOnly bool   `json:"failed_only"` // Only failed requests and error statuses
+	Limit      int    `json:"limit"`       // 100 by default, the newest traces are kept
+}
+
+type RequestTracePage struct {
+	Traces  []*RequestTrace `json:"traces"`
+	LastSeq int64           `json:"last_seq"`
+}
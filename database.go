@@ -15,50 +15,115 @@
 package main
 
 import (
-	"path/filepath"
+	"errors"
+	"fmt"
 	"time"
 
 	bolt "go.etcd.io/bbolt"
 )
 
 const (
-	BucketConnections = "connections"
-	BucketSettings    = "settings"
-	BucketHistories   = "histories"
+	BucketMeta         = "meta"
+	BucketConnections  = "connections"
+	BucketSettings     = "settings"
+	BucketHistories    = "histories"
+	BucketSavedQueries = "saved_queries"
 )
 
-func ConnectDatabase() (*bolt.DB, error) {
-	db, err := bolt.Open(filepath.Join(workDirectory, "config.db"), 0600, &bolt.Options{
+var (
+	DatabaseLockedError    = errors.New("config store is used by another instance")
+	DatabaseCorruptedError = errors.New("config store is corrupted")
+)
+
+// ConnectDatabase opens, checks and migrates config.db. A store locked by another process
+// returns DatabaseLockedError, a damaged one DatabaseCorruptedError.
+func ConnectDatabase(logger *Logger) (db *bolt.DB, err error) {
+	defer func() {
+		// bbolt panics on pages it cannot decode
+		if r := recover(); r != nil {
+			if db != nil {
+				_ = db.Close()
+			}
+			db, err = nil, fmt.Errorf("%w: %v", DatabaseCorruptedError, r)
+		}
+	}()
+	db, err = bolt.Open(databasePath(), 0600, &bolt.Options{
 		Timeout: time.Second * 3,
 		Logger:  nil,
 	})
-	if err != nil {
+	switch {
+	case errors.Is(err, bolt.ErrTimeout):
+		return nil, DatabaseLockedError
+	case errors.Is(err, bolt.ErrInvalid), errors.Is(err, bolt.ErrChecksum), errors.Is(err, bolt.ErrVersionMismatch):
+		return nil, fmt.Errorf("%w: %w", DatabaseCorruptedError, err)
+	case err != nil:
 		return nil, err
 	}
 
-	err = db.Update(func(tx *bolt.Tx) error {
-		if _, err := tx.CreateBucketIfNotExists([]byte(BucketConnections)); err != nil {
-			return err
+	if err = db.View(checkConsistency); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("%w: %w", DatabaseCorruptedError, err)
+	}
+	if err = migrateDatabase(db, logger); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// checkConsistency reads every record of the store, then checks the invariants of its pages.
+// tx.Check panics in a goroutine of its own on undecodable pages, reading the records first
+// turns those pages into a recoverable panic of the caller.
+func checkConsistency(tx *bolt.Tx) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
 		}
-		if _, err := tx.CreateBucketIfNotExists([]byte(BucketSettings)); err != nil {
-			return err
+	}()
+	err = tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+		_, err := readBucket(bucket)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// The checker runs until the channel is drained
+	for checkErr := range tx.Check() {
+		if err == nil {
+			err = checkErr
 		}
-		settingsBucket := tx.Bucket([]byte(BucketSettings))
-		if settingsBucket.Get([]byte("system")) == nil {
-			err := settingsBucket.Put([]byte("system"), defaultAppSetting.Marshal())
-			if err != nil {
-				return err
-			}
+	}
+	return err
+}
+
+// createBuckets creates the buckets and default settings missing from the store
+func createBuckets(tx *bolt.Tx) error {
+	for _, name := range []string{BucketMeta, BucketConnections, BucketSettings, BucketHistories, BucketSavedQueries} {
+		if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+			return err
 		}
-		if _, err := tx.CreateBucketIfNotExists([]byte(BucketHistories)); err != nil {
+	}
+	settingsBucket := tx.Bucket([]byte(BucketSettings))
+	if settingsBucket.Get([]byte("system")) == nil {
+		err := settingsBucket.Put([]byte("system"), defaultAppSetting.Marshal())
+		if err != nil {
 			return err
 		}
-		return nil
-	})
-	if err != nil {
-		_ = db.Close()
-		return nil, err
 	}
+	return nil
+}
 
-	return db, nil
+// view runs fn in a read transaction of the config store, the store is not swapped by a
+// restore or relocation meanwhile
+func (app *App) view(fn func(tx *bolt.Tx) error) error {
+	app.storeMu.RLock()
+	defer app.storeMu.RUnlock()
+	return app.db.View(fn)
+}
+
+// update runs fn in a write transaction of the config store, see view
+func (app *App) update(fn func(tx *bolt.Tx) error) error {
+	app.storeMu.RLock()
+	defer app.storeMu.RUnlock()
+	return app.db.Update(fn)
 }
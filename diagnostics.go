@@ -0,0 +1,297 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultStatsInterval = 1000
+	maxStatsInterval     = 60000
+)
+
+// GetDiagnostics runs SHOW DIAGNOSTICS and returns one group per diagnostics module.
+// When modules is not empty only the matching modules are returned.
+func (app *App) GetDiagnostics(connectName string, modules []string) ([]*StatsGroup, error) {
+	httpClient, err := app.getDialer(connectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", connectName)
+		return nil, err
+	}
+	groups, err := showStatsGroups(app.ctx, httpClient, "SHOW DIAGNOSTICS")
+	if err != nil {
+		app.logger.Error("show diagnostics failed", "reason", err, "name", connectName)
+		return nil, err
+	}
+	return filterStatsGroups(groups, modules), nil
+}
+
+// GetServerStats runs SHOW STATS (and optionally scrapes /debug/vars). In delta mode the
+// server is sampled twice and every numeric entry carries its per-second rate.
+func (app *App) GetServerStats(req *ServerStatsRequest) (*ServerStatsResponse, error) {
+	if req.ConnectName == "" {
+		return nil, errors.New("connect name required")
+	}
+	httpClient, err := app.getDialer(req.ConnectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", req.ConnectName)
+		return nil, err
+	}
+
+	first, err := sampleServerStats(app.ctx, httpClient, req)
+	if err != nil {
+		app.logger.Error("sample server stats failed", "reason", err, "name", req.ConnectName)
+		return nil, err
+	}
+	if !req.Delta {
+		return first, nil
+	}
+
+	interval := req.Interval
+	if interval <= 0 {
+		interval = defaultStatsInterval
+	}
+	if interval > maxStatsInterval {
+		interval = maxStatsInterval
+	}
+	select {
+	case <-app.ctx.Done():
+		return nil, app.ctx.Err()
+	case <-time.After(time.Duration(interval) * time.Millisecond):
+	}
+
+	second, err := sampleServerStats(app.ctx, httpClient, req)
+	if err != nil {
+		app.logger.Error("sample server stats failed", "reason", err, "name", req.ConnectName)
+		return nil, err
+	}
+	elapsed := float64(second.SampledAt - first.SampledAt)
+	applyStatsDelta(first.Stats, second.Stats, elapsed)
+	applyStatsDelta(first.DebugVars, second.DebugVars, elapsed)
+	second.Delta = true
+	second.Interval = elapsed
+	return second, nil
+}
+
+func sampleServerStats(ctx context.Context, httpClient HttpClient, req *ServerStatsRequest) (*ServerStatsResponse, error) {
+	var response = &ServerStatsResponse{}
+	if len(req.Modules) == 0 {
+		groups, err := showStatsGroups(ctx, httpClient, "SHOW STATS")
+		if err != nil {
+			return nil, err
+		}
+		response.Stats = groups
+	}
+	for _, module := range req.Modules {
+		command := "SHOW STATS FOR " + quoteString(module)
+		groups, err := showStatsGroups(ctx, httpClient, command)
+		if err != nil {
+			return nil, err
+		}
+		response.Stats = append(response.Stats, groups...)
+	}
+	if req.DebugVars {
+		data, err := httpClient.RawGet(ctx, "/debug/vars", nil)
+		if err != nil {
+			return nil, fmt.Errorf("scrape debug vars failed: %w", err)
+		}
+		groups, err := parseDebugVars(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse debug vars failed: %w", err)
+		}
+		response.DebugVars = filterStatsGroups(groups, req.Modules)
+	}
+	response.SampledAt = time.Now().UnixMilli()
+	return response, nil
+}
+
+// showStatsGroups executes a SHOW STATS / SHOW DIAGNOSTICS style command and turns every
+// row of every series into a key/value group
+func showStatsGroups(ctx context.Context, httpClient HttpClient, command string) ([]*StatsGroup, error) {
+	result, err := querySingle(ctx, httpClient, "", command)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", strings.ToLower(command), err)
+	}
+
+	var groups []*StatsGroup
+	for _, series := range result.Series {
+		for _, row := range series.Values {
+			group := &StatsGroup{Module: series.Name, Tags: series.Tags}
+			for i, column := range series.Columns {
+				if i >= len(row) {
+					break
+				}
+				group.Entries = append(group.Entries, newStatsEntry(column, row[i]))
+			}
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// parseDebugVars converts the expvar JSON document into groups. Entries in the
+// {"name": ..., "tags": ..., "values": ...} statistics layout keep their module and tags,
+// other objects are flattened and top-level scalars are collected in a "vars" group.
+func parseDebugVars(data []byte) ([]*StatsGroup, error) {
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	var (
+		groups  []*StatsGroup
+		scalars = &StatsGroup{Module: "vars"}
+	)
+	for _, key := range sortedKeys(document) {
+		var statistic struct {
+			Name   string            `json:"name"`
+			Tags   map[string]string `json:"tags"`
+			Values map[string]any    `json:"values"`
+		}
+		if err := json.Unmarshal(document[key], &statistic); err == nil && statistic.Name != "" && statistic.Values != nil {
+			group := &StatsGroup{Module: statistic.Name, Tags: statistic.Tags}
+			for _, name := range sortedKeys(statistic.Values) {
+				group.Entries = append(group.Entries, newStatsEntry(name, statistic.Values[name]))
+			}
+			groups = append(groups, group)
+			continue
+		}
+
+		var value any
+		if err := json.Unmarshal(document[key], &value); err != nil {
+			return nil, err
+		}
+		object, ok := value.(map[string]any)
+		if !ok {
+			scalars.Entries = append(scalars.Entries, newStatsEntry(key, value))
+			continue
+		}
+		group := &StatsGroup{Module: key}
+		flattenStatsObject(group, "", object)
+		groups = append(groups, group)
+	}
+	if len(scalars.Entries) > 0 {
+		groups = append(groups, scalars)
+	}
+	return groups, nil
+}
+
+func flattenStatsObject(group *StatsGroup, prefix string, object map[string]any) {
+	for _, name := range sortedKeys(object) {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		switch value := object[name].(type) {
+		case map[string]any:
+			flattenStatsObject(group, key, value)
+		case []any:
+			// Large histograms such as memstats.BySize are not useful as flat entries
+			continue
+		default:
+			group.Entries = append(group.Entries, newStatsEntry(key, value))
+		}
+	}
+}
+
+func newStatsEntry(key string, value any) *StatsEntry {
+	var entry = &StatsEntry{Key: key, Value: value}
+	switch value.(type) {
+	case float64, json.Number:
+		entry.Type = "number"
+	case string:
+		entry.Type = "string"
+	case bool:
+		entry.Type = "bool"
+	case nil:
+		entry.Type = "null"
+	default:
+		entry.Type = "string"
+		entry.Value = fmt.Sprint(value)
+	}
+	return entry
+}
+
+// applyStatsDelta fills Delta and Rate of the entries in current from the matching
+// entries in previous. elapsed is the time between the samples in milliseconds.
+func applyStatsDelta(previous, current []*StatsGroup, elapsed float64) {
+	if elapsed <= 0 {
+		return
+	}
+	var previousValues = make(map[string]float64)
+	for _, group := range previous {
+		id := statsGroupID(group)
+		for _, entry := range group.Entries {
+			if value, ok := entry.Value.(float64); ok {
+				previousValues[id+"\x00"+entry.Key] = value
+			}
+		}
+	}
+	for _, group := range current {
+		id := statsGroupID(group)
+		for _, entry := range group.Entries {
+			value, ok := entry.Value.(float64)
+			if !ok {
+				continue
+			}
+			before, ok := previousValues[id+"\x00"+entry.Key]
+			if !ok {
+				continue
+			}
+			entry.Delta = value - before
+			entry.Rate = entry.Delta / (elapsed / 1000)
+		}
+	}
+}
+
+func statsGroupID(group *StatsGroup) string {
+	var builder strings.Builder
+	builder.WriteString(group.Module)
+	for _, key := range sortedKeys(group.Tags) {
+		builder.WriteString("," + key + "=" + group.Tags[key])
+	}
+	return builder.String()
+}
+
+func filterStatsGroups(groups []*StatsGroup, modules []string) []*StatsGroup {
+	if len(modules) == 0 {
+		return groups
+	}
+	var filtered = make([]*StatsGroup, 0, len(groups))
+	for _, group := range groups {
+		for _, module := range modules {
+			if strings.EqualFold(group.Module, module) {
+				filtered = append(filtered, group)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func (app *App) ListDownsamples(connectName, database string) ([]*DownsamplePolicy, error) {
+	httpClient, err := app.getDialer(connectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", connectName)
+		return nil, err
+	}
+	policies, err := showDownsamples(app.ctx, httpClient, database)
+	if err != nil {
+		app.logger.Error("show downsamples failed", "reason", err, "db", database)
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (app *App) CreateDownsample(req *CreateDownsampleRequest) error {
+	if req.Database == "" || req.RetentionPolicy == "" {
+		return errors.New("database and retention policy required")
+	}
+	if len(req.Operators) == 0 {
+		return errors.New("at least one downsample operator required")
+	}
+	if req.Duration == "" {
+		return errors.New("downsample duration required")
+	}
+	if len(req.SampleIntervals) == 0 || len(req.SampleIntervals) != len(req.TimeIntervals) {
+		return errors.New("sample intervals and time intervals must be non-empty and of equal length")
+	}
+	if err := checkDurations("downsample duration", req.Duration); err != nil {
+		return err
+	}
+	if err := checkDurations("sample interval", req.SampleIntervals...); err != nil {
+		return err
+	}
+	if err := checkDurations("time interval", req.TimeIntervals...); err != nil {
+		return err
+	}
+	httpClient, err := app.getDialer(req.ConnectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", req.ConnectName)
+		return err
+	}
+
+	operators := make([]string, 0, len(req.Operators))
+	for _, operator := range req.Operators {
+		if operator.DataType == "" || len(operator.Functions) == 0 {
+			return errors.New("downsample operator requires a data type and functions")
+		}
+		for _, word := range append([]string{operator.DataType}, operator.Functions...) {
+			if !plainIdentRegexp.MatchString(word) {
+				return fmt.Errorf("invalid downsample operator: %s", word)
+			}
+		}
+		operators = append(operators, operator.DataType+"("+strings.Join(operator.Functions, ",")+")")
+	}
+	command := fmt.Sprintf("CREATE DOWNSAMPLE ON %s.%s (%s) WITH DURATION %s SAMPLEINTERVAL(%s) TIMEINTERVAL(%s)",
+		quoteIdentifier(req.Database), quoteIdentifier(req.RetentionPolicy), strings.Join(operators, ","),
+		req.Duration, strings.Join(req.SampleIntervals, ","), strings.Join(req.TimeIntervals, ","))
+
+	if err := app.guardBuiltCommand(req.ConnectName, req.Database, command); err != nil {
+		return err
+	}
+	if _, err := querySingle(app.ctx, httpClient, req.Database, command); err != nil {
+		app.logger.Error("create downsample failed", "reason", err, "db", req.Database, "rp", req.RetentionPolicy)
+		return fmt.Errorf("create downsample failed: %w", err)
+	}
+	app.logger.Info("create downsample", "db", req.Database, "rp", req.RetentionPolicy)
+	return nil
+}
+
+// DropDownsample drops the downsample policy of the retention policy. On production
+// connections it returns a confirmation first, the policy is dropped when called again with
+// the confirmation token.
+func (app *App) DropDownsample(connectName, database, retentionPolicy, confirmToken string) (*ExecuteConfirmation, error) {
+	command := "DROP DOWNSAMPLE ON " + quoteIdentifier(database) + "." + quoteIdentifier(retentionPolicy)
+	confirmation, err := app.guardCommand(connectName, database, confirmToken, command)
+	if err != nil || confirmation != nil {
+		return confirmation, err
+	}
+	httpClient, err := app.getDialer(connectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", connectName)
+		return nil, err
+	}
+	if _, err := querySingle(app.ctx, httpClient, database, command); err != nil {
+		app.logger.Error("drop downsample failed", "reason", err, "db", database, "rp", retentionPolicy)
+		return nil, fmt.Errorf("drop downsample failed: %w", err)
+	}
+	app.logger.Info("drop downsample", "db", database, "rp", retentionPolicy)
+	return nil, nil
+}
+
+// checkDurations refuses the values that are not InfluxQL durations before they are put
+// into a statement
+func checkDurations(name string, values ...string) error {
+	for _, value := range values {
+		if _, err := parseInfluxDuration(value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func showDownsamples(ctx context.Context, httpClient HttpClient, database string) ([]*DownsamplePolicy, error) {
+	result, err := querySingle(ctx, httpClient, database, "SHOW DOWNSAMPLES ON "+quoteIdentifier(database))
+	if err != nil {
+		return nil, fmt.Errorf("show downsamples failed: %w", err)
+	}
+
+	var policies []*DownsamplePolicy
+	for _, series := range result.Series {
+		columns := normalizedColumnIndex(series.Columns)
+		for _, row := range series.Values {
+			policy := &DownsamplePolicy{
+				Database:        database,
+				RetentionPolicy: rowString(row, columns, "rpname"),
+				Operators:       parseDownsampleOperators(rowString(row, columns, "fieldoperator")),
+				Duration:        rowString(row, columns, "duration"),
+				SampleIntervals: splitList(rowString(row, columns, "sampleinterval")),
+				TimeIntervals:   splitList(rowString(row, columns, "timeinterval")),
+			}
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+// parseDownsampleOperators parses "float{sum,last},integer{max,min}" (or the same with
+// parentheses) into one operator per data type
+func parseDownsampleOperators(text string) []*DownsampleOperator {
+	var (
+		operators []*DownsampleOperator
+		depth     int
+		start     int
+	)
+	flush := func(part string) {
+		part = strings.TrimSpace(part)
+		open := strings.IndexAny(part, "({[")
+		if part == "" || open == -1 {
+			return
+		}
+		operators = append(operators, &DownsampleOperator{
+			DataType:  strings.TrimSpace(part[:open]),
+			Functions: splitList(strings.TrimRight(part[open+1:], ")}] ")),
+		})
+	}
+	for i, c := range text {
+		switch c {
+		case '(', '{', '[':
+			depth++
+		case ')', '}', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				flush(text[start:i])
+				start = i + 1
+			}
+		}
+	}
+	flush(text[start:])
+	return operators
+}
+
+// normalizedColumnIndex maps lower-cased column names without '_' to their index, so
+// that e.g. "rpName" and "rp_name" are found under the same key
+func normalizedColumnIndex(columns []string) map[string]int {
+	var index = make(map[string]int, len(columns))
+	for i, col := range columns {
+		index[strings.ReplaceAll(strings.ToLower(col), "_", "")] = i
+	}
+	return index
+}
+
+func rowString(row []any, columns map[string]int, column string) string {
+	i, ok := columns[column]
+	if !ok || i >= len(row) || row[i] == nil {
+		return ""
+	}
+	if value, ok := row[i].(string); ok {
+		return value
+	}
+	return fmt.Sprint(row[i])
+}
+
+func splitList(text string) []string {
+	var items []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
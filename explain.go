@@ -0,0 +1,243 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// hotSpotRatio is the share of the total time from which a node's own time is a hot spot
+const hotSpotRatio = 0.3
+
+// planTreeGlyphs draw the plan tree, they are stripped to find the depth of a line
+const planTreeGlyphs = " \t.│├└─|`+-"
+
+// ExplainQuery runs EXPLAIN or EXPLAIN ANALYZE for the single SELECT statement of the
+// request and parses the textual plan into a node tree. analyze takes precedence over an
+// EXPLAIN prefix of the statement. EXPLAIN ANALYZE executes the query, so it is subject to
+// the safe mode of the connection like ExecuteCommand.
+func (app *App) ExplainQuery(data *ExecuteRequest, analyze bool) (*QueryPlan, error) {
+	if data.ConnectName == "" {
+		return nil, errors.New("connect name required")
+	}
+	httpClient, err := app.getDialer(data.ConnectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", data.ConnectName)
+		return nil, err
+	}
+	parsed, err := prepareCommand(data, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed.Statements) != 1 {
+		return nil, errors.New("explain requires a single statement")
+	}
+	stmt := parsed.Statements[0]
+	if stmt.Select == nil {
+		return nil, fmt.Errorf("explain requires a SELECT statement, got %s", stmt.Kind)
+	}
+	if analyze && stmt.Error != nil {
+		// What an executed statement would write cannot be told from a partial parse
+		return nil, fmt.Errorf("explain analyze refused for a statement with syntax errors: %w", stmt.Error)
+	}
+
+	// The SELECT without the EXPLAIN [ANALYZE] prefix it may have been written with
+	command := "EXPLAIN " + stmt.Text[stmt.Select.Start.Offset-stmt.Start.Offset:]
+	if analyze {
+		command = "EXPLAIN ANALYZE " + stmt.Text[stmt.Select.Start.Offset-stmt.Start.Offset:]
+	}
+	confirmation, err := app.guardStatements(data.ConnectName, data.Database, data.RetentionPolicy, data.ConfirmToken, ParseInfluxQL(command).Statements)
+	if err != nil {
+		return nil, err
+	}
+	if confirmation != nil {
+		return nil, errors.New("explain requires confirmation, run the statement from the editor")
+	}
+
+	result, err := querySingle(app.ctx, httpClient, data.Database, command)
+	if err != nil {
+		app.logger.Error("explain query failed", "reason", err, "command", command)
+		return nil, fmt.Errorf("explain query failed: %w", err)
+	}
+	var lines []string
+	for _, series := range result.Series {
+		for _, row := range series.Values {
+			for _, value := range row {
+				if text, ok := value.(string); ok {
+					lines = append(lines, strings.Split(text, "\n")...)
+				}
+			}
+		}
+	}
+	plan := ParsePlan(lines)
+	plan.Statement = command
+	plan.Analyze = analyze
+	return plan, nil
+}
+
+type planLine struct {
+	depth int
+	text  string
+}
+
+// ParsePlan builds the node tree of EXPLAIN output. Depth is taken from the indentation
+// and tree glyphs of each line; "key: value" lines without children become attributes of
+// their parent, as do the entries of "labels" groups.
+func ParsePlan(lines []string) *QueryPlan {
+	var (
+		plan   = &QueryPlan{Raw: lines}
+		parsed []planLine
+	)
+	for _, line := range lines {
+		text := strings.TrimLeft(line, planTreeGlyphs)
+		text = strings.TrimSpace(text)
+		if text == "" || strings.Trim(text, "-=") == "" || text == "QUERY PLAN" || text == "EXPLAIN ANALYZE" {
+			continue
+		}
+		depth := utf8.RuneCountInString(line[:strings.Index(line, text)])
+		parsed = append(parsed, planLine{depth: depth, text: text})
+	}
+
+	root := &PlanNode{Operator: "plan"}
+	type frame struct {
+		node  *PlanNode
+		depth int
+	}
+	var stack = []frame{{node: root, depth: -1}}
+	for i, line := range parsed {
+		for len(stack) > 1 && stack[len(stack)-1].depth >= line.depth {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].node
+		hasChildren := i+1 < len(parsed) && parsed[i+1].depth > line.depth
+
+		if key, value, ok := planAttribute(line.text); ok && !hasChildren {
+			parent.Attributes = append(parent.Attributes, &PlanAttribute{Key: key, Value: value})
+			continue
+		}
+		node := newPlanNode(line.text)
+		if node.Operator == "labels" {
+			// Entries of a labels group describe the parent
+			node = parent
+		} else {
+			parent.Children = append(parent.Children, node)
+		}
+		stack = append(stack, frame{node: node, depth: line.depth})
+	}
+
+	if len(root.Children) == 1 && len(root.Attributes) == 0 {
+		root = root.Children[0]
+	}
+	applyPlanMetrics(root)
+	plan.TotalTime = root.TimeMs
+	markHotSpots(root, root.TimeMs)
+	plan.Root = root
+	return plan
+}
+
+// planAttribute splits "key: value" and "key=value" lines
+func planAttribute(text string) (string, string, bool) {
+	if key, value, ok := strings.Cut(text, ": "); ok && key != "" && !strings.ContainsAny(key, "()[],") {
+		return strings.TrimSpace(key), strings.TrimSpace(value), true
+	}
+	if key, value, ok := strings.Cut(text, "="); ok && key != "" && !strings.ContainsAny(key, " ()[],") {
+		return key, strings.TrimSpace(value), true
+	}
+	if key, ok := strings.CutSuffix(text, ":"); ok && key != "" {
+		return key, "", true
+	}
+	return "", "", false
+}
+
+// newPlanNode parses operator lines such as "LogicalAggregate(max(v), cost=3.2)"
+func newPlanNode(text string) *PlanNode {
+	text = strings.TrimSuffix(text, ":")
+	node := &PlanNode{Operator: text}
+	if open := strings.IndexByte(text, '('); open > 0 && strings.HasSuffix(text, ")") {
+		node.Operator = strings.TrimSpace(text[:open])
+		node.Detail = text[open+1 : len(text)-1]
+		for _, part := range strings.Split(node.Detail, ",") {
+			if key, value, ok := strings.Cut(strings.TrimSpace(part), "="); ok {
+				node.Attributes = append(node.Attributes, &PlanAttribute{Key: key, Value: value})
+			}
+		}
+	}
+	return node
+}
+
+// applyPlanMetrics fills the time, rows and cost of each node from its attributes, a node
+// without time of its own spends the time of its children
+func applyPlanMetrics(node *PlanNode) {
+	var timePriority int
+	for _, attribute := range node.Attributes {
+		key := strings.ToLower(attribute.Key)
+		if priority := planTimePriority(key); priority > timePriority {
+			if d, err := time.ParseDuration(strings.ReplaceAll(attribute.Value, " ", "")); err == nil {
+				node.TimeMs = float64(d) / float64(time.Millisecond)
+				timePriority = priority
+			}
+			continue
+		}
+		switch key {
+		case "rows", "row_count", "num_rows", "output_rows", "points":
+			if rows, err := strconv.ParseInt(attribute.Value, 10, 64); err == nil {
+				node.Rows = rows
+			}
+		case "cost":
+			if cost, err := strconv.ParseFloat(attribute.Value, 64); err == nil {
+				node.Cost = cost
+			}
+		}
+	}
+	var childrenTime float64
+	for _, child := range node.Children {
+		applyPlanMetrics(child)
+		childrenTime += child.TimeMs
+	}
+	if timePriority == 0 {
+		node.TimeMs = childrenTime
+	}
+	node.SelfTimeMs = max(node.TimeMs-childrenTime, 0)
+}
+
+// planTimePriority ranks the attributes holding the time of a node, 0 for other attributes
+func planTimePriority(key string) int {
+	switch {
+	case key == "total_time":
+		return 4
+	case key == "execution_time":
+		return 3
+	case key == "time":
+		return 2
+	case strings.HasSuffix(key, "_time") && key != "planning_time":
+		return 1
+	}
+	return 0
+}
+
+func markHotSpots(node *PlanNode, total float64) {
+	if total > 0 {
+		node.Percent = node.TimeMs / total * 100
+		node.Hot = node.SelfTimeMs >= total*hotSpotRatio
+	}
+	for _, child := range node.Children {
+		markHotSpots(child, total)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	LabelProduction = "production"
+	confirmationTTL = 5 * time.Minute
+)
+
+var (
+	ReadOnlyConnectError = errors.New("connection is read-only")
+)
+
+type pendingConfirmation struct {
+	connectName     string
+	database        string
+	retentionPolicy string
+	command         string // Destructive statements confirmed
+	expiresAt       time.Time
+}
+
+// guardStatements applies the safe mode of the connection to the statements of a command.
+// Read-only connections refuse anything but reads; on production connections destructive
+// statements are only executed with the token of a confirmation issued for the same
+// statements on the same database and retention policy, otherwise a new confirmation is
+// returned for the user to accept. Statements with syntax errors are neither reads nor
+// known to be harmless, they are handled like destructive ones.
+func (app *App) guardStatements(connectName, database, retentionPolicy, confirmToken string, statements []*Statement) (*ExecuteConfirmation, error) {
+	cc, err := app.GetConnect(connectName)
+	if err != nil {
+		return nil, err
+	}
+
+	var destructive []*Statement
+	for _, stmt := range statements {
+		unknown := stmt.Error != nil || stmt.Category == CategoryUnknown
+		if cc.ReadOnly && unknown {
+			app.logger.Warn("refuse unknown statement on read-only connection", "name", connectName, "kind", stmt.Kind)
+			return nil, fmt.Errorf("%w: statements with syntax errors are not allowed", ReadOnlyConnectError)
+		}
+		if cc.ReadOnly && stmt.Category != CategoryRead {
+			app.logger.Warn("refuse statement on read-only connection", "name", connectName, "kind", stmt.Kind)
+			return nil, fmt.Errorf("%w: %s statements are not allowed", ReadOnlyConnectError, stmt.Kind)
+		}
+		if stmt.Destructive || unknown {
+			destructive = append(destructive, stmt)
+		}
+	}
+	if len(destructive) == 0 || !strings.EqualFold(cc.Label, LabelProduction) {
+		return nil, nil
+	}
+	// Confirmations are bound to the destructive statements as executed, with their variables
+	// expanded. The time range of a request only changes SELECTs, which are never destructive.
+	texts := make([]string, 0, len(destructive))
+	for _, stmt := range destructive {
+		texts = append(texts, stmt.Text)
+	}
+	command := strings.Join(texts, ";\n")
+	scope := &pendingConfirmation{connectName: connectName, database: database, retentionPolicy: retentionPolicy, command: command}
+	if confirmToken != "" && app.consumeConfirmation(confirmToken, scope) {
+		app.logger.Info("destructive command confirmed", "name", connectName, "db", database, "command", command)
+		return nil, nil
+	}
+
+	token, err := newConfirmToken()
+	if err != nil {
+		return nil, err
+	}
+	app.pruneConfirmations()
+	scope.expiresAt = time.Now().Add(confirmationTTL)
+	app.confirmations.Store(token, scope)
+	return &ExecuteConfirmation{
+		Token:       token,
+		ConnectName: connectName,
+		Label:       cc.Label,
+		Statements:  destructive,
+		ExpiresAt:   scope.expiresAt.UnixMilli(),
+	}, nil
+}
+
+// consumeConfirmation checks a token against the command and scope it was issued for, a
+// token can be used only once
+func (app *App) consumeConfirmation(token string, scope *pendingConfirmation) bool {
+	value, ok := app.confirmations.LoadAndDelete(token)
+	if !ok {
+		return false
+	}
+	pending := value.(*pendingConfirmation)
+	return pending.connectName == scope.connectName && pending.database == scope.database &&
+		pending.retentionPolicy == scope.retentionPolicy && pending.command == scope.command &&
+		time.Now().Before(pending.expiresAt)
+}
+
+// guardCommand applies the safe mode of the connection to a statement built by the
+// application, such as the DROP of an object from the tree
+func (app *App) guardCommand(connectName, database, confirmToken, command string) (*ExecuteConfirmation, error) {
+	return app.guardStatements(connectName, database, "", confirmToken, ParseInfluxQL(command).Statements)
+}
+
+// guardBuiltCommand applies the safe mode of the connection to a statement built by the
+// application from user input, such as the CREATE of a continuous query. The APIs building
+// them have no confirmation round-trip, a statement that would need one is refused.
+func (app *App) guardBuiltCommand(connectName, database, command string) error {
+	confirmation, err := app.guardCommand(connectName, database, "", command)
+	if err != nil {
+		return err
+	}
+	if confirmation != nil {
+		app.confirmations.Delete(confirmation.Token)
+		return fmt.Errorf("statement requires confirmation, run it from the editor: %s", command)
+	}
+	return nil
+}
+
+// pruneConfirmations drops the confirmations that expired without being used
+func (app *App) pruneConfirmations() {
+	now := time.Now()
+	app.confirmations.Range(func(key, value any) bool {
+		if now.After(value.(*pendingConfirmation).expiresAt) {
+			app.confirmations.Delete(key)
+		}
+		return true
+	})
+}
+
+func newConfirmToken() (string, error) {
+	token, err := randomHex(16)
+	if err != nil {
+		return "", fmt.Errorf("generate confirm token failed: %w", err)
+	}
+	return token, nil
+}
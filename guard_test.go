@@ -0,0 +1,200 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// setupGuardTest opens a config store in a temporary directory with a read-only, a
+// production and an unlabelled connection
+func setupGuardTest(t *testing.T) *App {
+	t.Helper()
+	workDirectory = t.TempDir()
+	app := &App{logger: NewLogger(), schemas: NewSchemaCache()}
+	t.Cleanup(app.logger.Close)
+	if err := app.openDatabase(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { app.db.Close() })
+	for _, cc := range []*ConnectConfig{
+		{Name: "readonly", Address: "127.0.0.1:8086", ReadOnly: true},
+		{Name: "production", Address: "127.0.0.1:8086", Label: LabelProduction},
+		{Name: "dev", Address: "127.0.0.1:8086"},
+	} {
+		if err := app.AddConnect(cc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return app
+}
+
+func guardQuery(app *App, connectName, database, token, query string) (*ExecuteConfirmation, error) {
+	return app.guardStatements(connectName, database, "", token, ParseInfluxQL(query).Statements)
+}
+
+func TestGuardReadOnly(t *testing.T) {
+	app := setupGuardTest(t)
+
+	var tests = []struct {
+		query   string
+		allowed bool
+	}{
+		{`SELECT * FROM cpu`, true},
+		{`SHOW MEASUREMENTS; EXPLAIN SELECT * INTO t FROM cpu`, true},
+		{`SELECT * INTO t FROM cpu`, false},
+		{`EXPLAIN ANALYZE SELECT * INTO t FROM cpu`, false},
+		{`INSERT cpu value=1`, false},
+		{`CREATE DATABASE db`, false},
+		{`DROP MEASUREMENT cpu`, false},
+		{`SELECT * FROM cpu; DROP MEASUREMENT cpu`, false},
+		{`SELECT * FROM cpu WHERE`, false},
+		{`FOO BAR`, false},
+	}
+	for _, tt := range tests {
+		confirmation, err := guardQuery(app, "readonly", "db", "", tt.query)
+		if confirmation != nil {
+			t.Errorf("%s: confirmation issued on a read-only connection", tt.query)
+		}
+		if tt.allowed && err != nil {
+			t.Errorf("%s: refused: %v", tt.query, err)
+		}
+		if !tt.allowed && !errors.Is(err, ReadOnlyConnectError) {
+			t.Errorf("%s: error = %v, want %v", tt.query, err, ReadOnlyConnectError)
+		}
+	}
+}
+
+func TestGuardConfirmation(t *testing.T) {
+	app := setupGuardTest(t)
+
+	const drop = `SELECT * FROM cpu; DROP MEASUREMENT cpu`
+	confirmation, err := guardQuery(app, "production", "db", "", drop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confirmation == nil || confirmation.Token == "" {
+		t.Fatal("DROP on a production connection was not held for confirmation")
+	}
+	if len(confirmation.Statements) != 1 || confirmation.Statements[0].Kind != "DROP MEASUREMENT" {
+		t.Errorf("confirmation statements = %v, want the DROP only", statementTexts(&ParseResult{Statements: confirmation.Statements}))
+	}
+
+	// A token only confirms the statements and the database it was issued for
+	for _, other := range []struct{ database, query string }{
+		{"other", drop},
+		{"db", `DROP MEASUREMENT mem`},
+	} {
+		confirmation, err := guardQuery(app, "production", "db", "", drop)
+		if err != nil {
+			t.Fatal(err)
+		}
+		retry, err := guardQuery(app, "production", other.database, confirmation.Token, other.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if retry == nil {
+			t.Errorf("token confirmed %q on %s", other.query, other.database)
+		}
+	}
+
+	confirmation, err = guardQuery(app, "production", "db", "", drop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retry, err := guardQuery(app, "production", "db", confirmation.Token, drop)
+	if err != nil || retry != nil {
+		t.Fatalf("confirmed command = %v, %v, want it executed", retry, err)
+	}
+	// Tokens are single-use
+	retry, err = guardQuery(app, "production", "db", confirmation.Token, drop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retry == nil {
+		t.Error("token was accepted twice")
+	}
+}
+
+func TestGuardStatementsByLabel(t *testing.T) {
+	app := setupGuardTest(t)
+
+	var tests = []struct {
+		connectName string
+		query       string
+		confirm     bool
+	}{
+		{"production", `SELECT * FROM cpu`, false},
+		{"production", `INSERT cpu value=1`, false},
+		{"production", `CREATE DATABASE db`, false},
+		{"production", `DELETE FROM cpu`, true},
+		{"production", `ALTER RETENTION POLICY rp ON db DURATION 1d`, true},
+		{"production", `SELECT * FROM cpu WHERE`, true},
+		{"production", `FOO BAR`, true},
+		{"dev", `DROP DATABASE db`, false},
+		{"dev", `SELECT * FROM cpu WHERE`, false},
+	}
+	for _, tt := range tests {
+		confirmation, err := guardQuery(app, tt.connectName, "db", "", tt.query)
+		if err != nil {
+			t.Errorf("%s on %s: %v", tt.query, tt.connectName, err)
+			continue
+		}
+		if (confirmation != nil) != tt.confirm {
+			t.Errorf("%s on %s: confirmation = %v, want %v", tt.query, tt.connectName, confirmation != nil, tt.confirm)
+		}
+	}
+}
+
+// TestGuardBuiltCommand refuses built statements needing a confirmation without leaving a
+// token behind
+func TestGuardBuiltCommand(t *testing.T) {
+	app := setupGuardTest(t)
+
+	if err := app.guardBuiltCommand("production", "db", `CREATE DATABASE db`); err != nil {
+		t.Errorf("CREATE refused on production: %v", err)
+	}
+	if err := app.guardBuiltCommand("production", "db", `DROP MEASUREMENT cpu`); err == nil {
+		t.Error("DROP built on production was not refused")
+	}
+	if err := app.guardBuiltCommand("readonly", "db", `CREATE DATABASE db`); !errors.Is(err, ReadOnlyConnectError) {
+		t.Errorf("CREATE on read-only: error = %v, want %v", err, ReadOnlyConnectError)
+	}
+	app.confirmations.Range(func(key, value any) bool {
+		t.Errorf("confirmation %v left behind", key)
+		return true
+	})
+}
+
+func TestCheckSyntax(t *testing.T) {
+	var tests = []struct {
+		query        string
+		ignoreErrors bool
+		refused      bool
+	}{
+		{`SELECT * FROM cpu`, false, false},
+		{`SELECT * FROM cpu WHERE`, false, true},
+		{`SELECT * FROM cpu WHERE`, true, false},
+		{"INSERT cpu value=1\nSELECT * FROM", true, false},
+		{`INSERT`, true, true},
+	}
+	for _, tt := range tests {
+		err := checkSyntax(ParseInfluxQL(tt.query), tt.ignoreErrors)
+		if (err != nil) != tt.refused {
+			t.Errorf("%q ignoring errors %v: error = %v, want refused %v", tt.query, tt.ignoreErrors, err, tt.refused)
+		}
+	}
+}
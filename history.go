@@ -0,0 +1,277 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	historyIDLength        = 20
+	defaultHistoryPageSize = 50
+	maxHistoryPageSize     = 500
+)
+
+var historyIDState struct {
+	sync.Mutex
+	last int64
+}
+
+// newHistoryID returns a time-ordered ID: Unix nanoseconds zero-padded to a fixed width,
+// so that the byte order of the keys in the histories bucket is chronological. IDs are
+// strictly increasing even when created within the same nanosecond.
+func newHistoryID(t time.Time) string {
+	historyIDState.Lock()
+	defer historyIDState.Unlock()
+	id := max(t.UnixNano(), historyIDState.last+1)
+	historyIDState.last = id
+	return formatHistoryID(id)
+}
+
+func formatHistoryID(nanoseconds int64) string {
+	return fmt.Sprintf("%0*d", historyIDLength, nanoseconds)
+}
+
+// GetHistories returns the newest histories, at most MaxHistoryCount of the settings
+func (app *App) GetHistories() ([]*History, error) {
+	var limit int
+	if settings, err := app.GetSetting(); err == nil {
+		limit = settings.MaxHistoryCount
+	}
+	var histories = make([]*History, 0)
+	err := app.view(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(BucketHistories)).Cursor()
+		for k, v := cursor.Last(); k != nil && (limit <= 0 || len(histories) < limit); k, v = cursor.Prev() {
+			var history = &History{}
+			if err := json.Unmarshal(v, history); err != nil {
+				return err
+			}
+			histories = append(histories, history)
+		}
+		return nil
+	})
+	if err != nil {
+		app.logger.Error("list histories failed", "reason", err)
+		return nil, err
+	}
+	return histories, nil
+}
+
+// AddHistory saves the history and drops the oldest records beyond MaxHistoryCount
+func (app *App) AddHistory(history *History) error {
+	if history.ID == "" {
+		history.ID = newHistoryID(time.Now())
+	}
+	data, err := json.Marshal(history)
+	if err != nil {
+		app.logger.Error("marshal history failed", "reason", err)
+		return err
+	}
+
+	var limit int
+	if settings, err := app.GetSetting(); err == nil {
+		limit = settings.MaxHistoryCount
+	}
+	err = app.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketHistories))
+		var added int
+		if bucket.Get([]byte(history.ID)) == nil {
+			added = 1
+		}
+		if err := bucket.Put([]byte(history.ID), data); err != nil {
+			return err
+		}
+		return trimHistories(bucket, added, limit)
+	})
+	if err != nil {
+		app.logger.Error("save history failed", "reason", err)
+		return err
+	}
+	return nil
+}
+
+// trimHistories deletes the oldest records beyond limit, a limit of 0 keeps everything.
+// Bucket statistics only count committed keys, added is the number of records put by the
+// current transaction.
+func trimHistories(bucket *bolt.Bucket, added, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+	// Keys are time-ordered, the first ones are the oldest
+	cursor := bucket.Cursor()
+	for excess := bucket.Stats().KeyN + added - limit; excess > 0; excess-- {
+		if k, _ := cursor.First(); k == nil {
+			return nil
+		}
+		if err := cursor.Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchHistory pages through the histories from newest to oldest. All words of the text
+// filter must occur in the query or the error; the NextCursor of a page continues the
+// search with the records before it.
+func (app *App) SearchHistory(filter *HistoryFilter) (*HistoryPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultHistoryPageSize
+	}
+	limit = min(limit, maxHistoryPageSize)
+	words := strings.Fields(strings.ToLower(filter.Text))
+
+	var page = &HistoryPage{Items: make([]*History, 0, limit)}
+	err := app.view(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(BucketHistories)).Cursor()
+
+		// Position at the newest record to consider, keys are ordered by time
+		var k, v []byte
+		switch {
+		case filter.Cursor != "":
+			k, v = cursor.Seek([]byte(filter.Cursor))
+			if k == nil {
+				k, v = cursor.Last()
+			}
+			if k != nil && string(k) >= filter.Cursor {
+				k, v = cursor.Prev()
+			}
+		case filter.Until > 0:
+			until := formatHistoryID(time.UnixMilli(filter.Until + 1).UnixNano())
+			k, v = cursor.Seek([]byte(until))
+			if k == nil {
+				k, v = cursor.Last()
+			}
+			if k != nil && string(k) >= until {
+				k, v = cursor.Prev()
+			}
+		default:
+			k, v = cursor.Last()
+		}
+
+		for ; k != nil; k, v = cursor.Prev() {
+			var history = &History{}
+			if err := json.Unmarshal(v, history); err != nil {
+				return err
+			}
+			if filter.Since > 0 && history.Timestamp < filter.Since {
+				break
+			}
+			if !filter.matches(history, words) {
+				continue
+			}
+			if len(page.Items) == limit {
+				page.NextCursor = page.Items[len(page.Items)-1].ID
+				break
+			}
+			page.Items = append(page.Items, history)
+		}
+		return nil
+	})
+	if err != nil {
+		app.logger.Error("search histories failed", "reason", err)
+		return nil, err
+	}
+	return page, nil
+}
+
+func (filter *HistoryFilter) matches(history *History, words []string) bool {
+	if filter.ConnectName != "" && history.ConnectName != filter.ConnectName {
+		return false
+	}
+	if filter.Database != "" && history.Database != filter.Database {
+		return false
+	}
+	if filter.Success != nil && history.Success != *filter.Success {
+		return false
+	}
+	if filter.Until > 0 && history.Timestamp > filter.Until {
+		return false
+	}
+	if len(words) == 0 {
+		return true
+	}
+	text := strings.ToLower(history.Query + "\n" + history.Error)
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// loadHistories reads every history record accepted by filter, oldest first
+func (app *App) loadHistories(filter func(*History) bool) ([]*History, error) {
+	var histories []*History
+	err := app.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketHistories))
+		return bucket.ForEach(func(k, v []byte) error {
+			var history = &History{}
+			if err := json.Unmarshal(v, history); err != nil {
+				return err
+			}
+			if filter == nil || filter(history) {
+				histories = append(histories, history)
+			}
+			return nil
+		})
+	})
+	return histories, err
+}
+
+// migrateHistoryKeys re-keys histories saved under Unix millisecond IDs, whose byte order
+// is not chronological, with time-ordered IDs
+func migrateHistoryKeys(tx *bolt.Tx) error {
+	bucket := tx.Bucket([]byte(BucketHistories))
+	var legacy [][]byte
+	err := bucket.ForEach(func(k, v []byte) error {
+		if len(k) != historyIDLength {
+			legacy = append(legacy, slices.Clone(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range legacy {
+		var history = &History{}
+		if err := json.Unmarshal(bucket.Get(k), history); err != nil {
+			return err
+		}
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+		id := time.UnixMilli(history.Timestamp).UnixNano()
+		for bucket.Get([]byte(formatHistoryID(id))) != nil {
+			id++
+		}
+		history.ID = formatHistoryID(id)
+		data, err := json.Marshal(history)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put([]byte(history.ID), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
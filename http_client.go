@@ -24,9 +24,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -37,21 +38,31 @@ var _ HttpClient = (*HttpClientCreator)(nil)
 
 type HttpClient interface {
 	SetDebug(debug bool)
+	// SetTracer receives the traces of the requests made in debug mode
+	SetTracer(tracer RequestTracer)
 	SetAuth(username, password string)
 	Ping() error
 	Query(context.Context, *opengemini.Query) (*opengemini.QueryResult, error)
+	// QueryProfiled is Query measuring the response of the server
+	QueryProfiled(context.Context, *opengemini.Query) (*opengemini.QueryResult, *QueryProfile, error)
 	Write(ctx context.Context, database, retentionPolicy, raw, precision string) error
 	Databases(ctx context.Context) ([]string, error)
 	RetentionPolicies(ctx context.Context, database string) ([]*RetentionPolicy, error)
 	Measurements(ctx context.Context, database string) ([]string, error)
+	TagKeys(ctx context.Context, database, measurement string) ([]string, error)
+	FieldKeys(ctx context.Context, database, measurement string) ([]*FieldKey, error)
+	TagValues(ctx context.Context, database, measurement, key string, limit int) ([]string, error)
+	RawGet(ctx context.Context, path string, params url.Values) ([]byte, error)
 	Close() error
 }
 
 type HttpClientCreator struct {
 	HostPort  string
+	name      string // Connection name, for the traces
 	client    *http.Client
 	basic     string
 	debug     bool
+	tracer    RequestTracer
 	sshTunnel *SSHTunnel
 }
 
@@ -143,6 +154,112 @@ func (h *HttpClientCreator) Measurements(ctx context.Context, database string) (
 	return measurements, nil
 }
 
+// TagKeys returns the tag keys of a measurement, or of all measurements when measurement is empty
+func (h *HttpClientCreator) TagKeys(ctx context.Context, database, measurement string) ([]string, error) {
+	command := "SHOW TAG KEYS"
+	if measurement != "" {
+		command += " FROM " + quoteIdentifier(measurement)
+	}
+	result, err := querySingle(ctx, h, database, command)
+	if err != nil {
+		return nil, fmt.Errorf("show tag keys failed: %w", err)
+	}
+	return uniqueFirstColumn(result), nil
+}
+
+// FieldKeys returns the field keys and types of a measurement, or of all measurements when measurement is empty
+func (h *HttpClientCreator) FieldKeys(ctx context.Context, database, measurement string) ([]*FieldKey, error) {
+	command := "SHOW FIELD KEYS"
+	if measurement != "" {
+		command += " FROM " + quoteIdentifier(measurement)
+	}
+	result, err := querySingle(ctx, h, database, command)
+	if err != nil {
+		return nil, fmt.Errorf("show field keys failed: %w", err)
+	}
+
+	var (
+		fieldKeys []*FieldKey
+		seen      = make(map[string]bool)
+	)
+	for _, series := range result.Series {
+		for _, row := range series.Values {
+			if len(row) < 2 {
+				continue
+			}
+			name, ok := row[0].(string)
+			if !ok || seen[name] {
+				continue
+			}
+			seen[name] = true
+			fieldType, _ := row[1].(string)
+			fieldKeys = append(fieldKeys, &FieldKey{Name: name, Type: fieldType})
+		}
+	}
+	return fieldKeys, nil
+}
+
+// TagValues returns up to limit values of a tag key, limit <= 0 means no limit
+func (h *HttpClientCreator) TagValues(ctx context.Context, database, measurement, key string, limit int) ([]string, error) {
+	command := "SHOW TAG VALUES"
+	if measurement != "" {
+		command += " FROM " + quoteIdentifier(measurement)
+	}
+	command += " WITH KEY = " + quoteIdentifier(key)
+	if limit > 0 {
+		command += " LIMIT " + strconv.Itoa(limit)
+	}
+	result, err := querySingle(ctx, h, database, command)
+	if err != nil {
+		return nil, fmt.Errorf("show tag values failed: %w", err)
+	}
+
+	var (
+		values []string
+		seen   = make(map[string]bool)
+	)
+	for _, series := range result.Series {
+		valueIdx := slices.Index(series.Columns, "value")
+		if valueIdx == -1 {
+			continue
+		}
+		for _, row := range series.Values {
+			if len(row) <= valueIdx {
+				continue
+			}
+			value, ok := row[valueIdx].(string)
+			if !ok || seen[value] {
+				continue
+			}
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// uniqueFirstColumn collects the distinct string values of the first column of all series
+func uniqueFirstColumn(result *opengemini.SeriesResult) []string {
+	var (
+		values []string
+		seen   = make(map[string]bool)
+	)
+	for _, series := range result.Series {
+		for _, row := range series.Values {
+			if len(row) == 0 {
+				continue
+			}
+			value, ok := row[0].(string)
+			if !ok || seen[value] {
+				continue
+			}
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func (h *HttpClientCreator) Databases(ctx context.Context) ([]string, error) {
 	response, err := h.Query(ctx, &opengemini.Query{
 		Command: "SHOW DATABASES",
@@ -182,8 +299,12 @@ func (h *HttpClientCreator) SetDebug(debug bool) {
 	h.debug = debug
 }
 
+func (h *HttpClientCreator) SetTracer(tracer RequestTracer) {
+	h.tracer = tracer
+}
+
 func NewHttpClient(cfg *ConnectConfig, logger *Logger) (HttpClient, error) {
-	var client = &HttpClientCreator{client: &http.Client{
+	var client = &HttpClientCreator{name: cfg.Name, client: &http.Client{
 		Timeout: 600 * time.Second,
 	}}
 
@@ -289,6 +410,11 @@ func (h *HttpClientCreator) Ping() error {
 }
 
 func (h *HttpClientCreator) Query(ctx context.Context, query *opengemini.Query) (*opengemini.QueryResult, error) {
+	result, _, err := h.QueryProfiled(ctx, query)
+	return result, err
+}
+
+func (h *HttpClientCreator) QueryProfiled(ctx context.Context, query *opengemini.Query) (*opengemini.QueryResult, *QueryProfile, error) {
 	urlPath := h.HostPort + "/query"
 
 	var queryValues = make(url.Values)
@@ -297,25 +423,32 @@ func (h *HttpClientCreator) Query(ctx context.Context, query *opengemini.Query)
 	queryValues.Add("q", query.Command)
 	queryValues.Add("epoch", query.Precision.Epoch())
 
+	startTime := time.Now()
 	response, err := h.innerRequest(ctx, http.MethodPost, urlPath, strings.NewReader(queryValues.Encode()))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer response.Body.Close()
+	profile := &QueryProfile{
+		TimeToFirstByte: float64(time.Since(startTime).Microseconds()) / 1000,
+		ServerVersion:   response.Header.Get("X-Influxdb-Version"),
+		RequestID:       response.Header.Get("Request-Id"),
+	}
 
 	data, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, err
+		return nil, profile, err
 	}
+	profile.ResponseBytes = int64(len(data))
 	if response.StatusCode != http.StatusOK {
-		return nil, errors.New("response status_code: " + response.Status + ", body: " + string(data))
+		return nil, profile, errors.New("response status_code: " + response.Status + ", body: " + string(data))
 	}
 	var qr = new(opengemini.QueryResult)
 	err = json.Unmarshal(data, qr)
 	if err != nil {
-		return nil, err
+		return nil, profile, err
 	}
-	return qr, nil
+	return qr, profile, nil
 }
 
 func (h *HttpClientCreator) Write(ctx context.Context, database, retentionPolicy, raw, precision string) error {
@@ -343,6 +476,54 @@ func (h *HttpClientCreator) Write(ctx context.Context, database, retentionPolicy
 	return nil
 }
 
+// querySingle runs a single-statement command and returns its statement result,
+// folding response level and statement level errors into the returned error
+func querySingle(ctx context.Context, httpClient HttpClient, database, command string) (*opengemini.SeriesResult, error) {
+	response, err := httpClient.Query(ctx, &opengemini.Query{
+		Database: database,
+		Command:  command,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	if len(response.Results) == 0 {
+		return &opengemini.SeriesResult{}, nil
+	}
+	if response.Results[0].Error != "" {
+		return nil, errors.New(response.Results[0].Error)
+	}
+	return response.Results[0], nil
+}
+
+// RawGet issues a GET request to the given server path and returns the raw response body
+func (h *HttpClientCreator) RawGet(ctx context.Context, path string, params url.Values) ([]byte, error) {
+	u, err := url.Parse(h.HostPort + path)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) > 0 {
+		u.RawQuery = params.Encode()
+	}
+
+	response, err := h.innerRequest(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New("response status_code: " + response.Status + ", body: " + string(data))
+	}
+	return data, nil
+}
+
 func (h *HttpClientCreator) innerRequest(ctx context.Context, method, urlPath string, reader io.Reader) (*http.Response, error) {
 	request, err := http.NewRequestWithContext(ctx, method, urlPath, reader)
 	if err != nil {
@@ -354,18 +535,16 @@ func (h *HttpClientCreator) innerRequest(ctx context.Context, method, urlPath st
 		request.Header.Set("Authorization", "Basic "+h.basic)
 	}
 
-	if h.debug {
-		dumpRequest, _ := httputil.DumpRequest(request, true)
-		fmt.Printf("---------- REQUEST DEBUG ----------\n%s\n---------- REQUEST DEBUG ----------\n", string(dumpRequest))
+	if !h.debug || h.tracer == nil {
+		return h.client.Do(request)
 	}
-
-	response, err := h.client.Do(request)
-
-	if h.debug {
-		dumpResponse, _ := httputil.DumpResponse(response, true)
-		fmt.Printf("---------- RESPONSE DEBUG ----------\n%s\n---------- RESPONSE DEBUG ----------\n", string(dumpResponse))
+	trace, err := traceRequest(h.name, request)
+	if err != nil {
+		return nil, err
 	}
-
+	start := time.Now()
+	response, err := h.client.Do(request)
+	response = finishTrace(trace, start, response, err, h.tracer)
 	return response, err
 }
 
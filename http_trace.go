@@ -0,0 +1,288 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	traceRingSize         = 500
+	defaultTraceListLimit = 100
+	maxTraceBody          = 4096 // Bytes of a body kept in a trace
+	redacted              = "[REDACTED]"
+)
+
+// RequestTracer receives the trace of every request of an HttpClient in debug mode, once
+// the response body is closed or the request failed. Traces are redacted before they are
+// handed over.
+type RequestTracer interface {
+	TraceRequest(trace *RequestTrace)
+}
+
+var (
+	// Headers carrying credentials, the scheme of the authorization ones is kept
+	secretHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	// Query parameters carrying credentials, u/p is the basic auth of the query string
+	secretParams = []string{"p", "password", "token", "access_token"}
+	// PASSWORD 'x' of CREATE USER and SET PASSWORD FOR u = 'x'
+	passwordLiteral = regexp.MustCompile(`(?i)(\bPASSWORD\b(?:\s+FOR\s+(?:"(?:[^"\\]|\\.)*"|\S+)\s*=)?\s*)'(?:[^'\\]|\\.)*'`)
+)
+
+// traceRequest prepares the trace of request, the body of the request is read and replaced
+func traceRequest(name string, request *http.Request) (*RequestTrace, error) {
+	trace := &RequestTrace{
+		Time:           time.Now().UnixMilli(),
+		Connection:     name,
+		Method:         request.Method,
+		URL:            redactURL(request.URL),
+		RequestHeaders: redactHeaders(request.Header),
+	}
+	if request.Body == nil || request.Body == http.NoBody {
+		return trace, nil
+	}
+	// Bodies of the client are built in memory, reading them does not cost a copy worth mentioning
+	data, err := io.ReadAll(request.Body)
+	_ = request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	request.Body = io.NopCloser(strings.NewReader(string(data)))
+	trace.RequestBytes = int64(len(data))
+	body := redactBody(request.URL.Path, string(data))
+	trace.RequestBody = truncateBody(body, len(body))
+	return trace, nil
+}
+
+// finishTrace records the response, or the error when no response was received
+func finishTrace(trace *RequestTrace, start time.Time, response *http.Response, err error, tracer RequestTracer) *http.Response {
+	trace.TimeToFirstByte = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		trace.Duration = trace.TimeToFirstByte
+		trace.Error = err.Error()
+		tracer.TraceRequest(trace)
+		return response
+	}
+	trace.Status = response.StatusCode
+	trace.ResponseHeaders = redactHeaders(response.Header)
+	response.Body = &tracedBody{ReadCloser: response.Body, trace: trace, start: start, tracer: tracer}
+	return response
+}
+
+// tracedBody keeps the head of the response body and hands the trace over when closed
+type tracedBody struct {
+	io.ReadCloser
+	trace  *RequestTrace
+	start  time.Time
+	tracer RequestTracer
+	head   []byte
+	size   int64
+	once   sync.Once
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if keep := min(n, maxTraceBody-len(b.head)); keep > 0 {
+		b.head = append(b.head, p[:keep]...)
+	}
+	if err != nil && err != io.EOF {
+		b.trace.Error = err.Error()
+	}
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.trace.Duration = float64(time.Since(b.start).Microseconds()) / 1000
+		b.trace.ResponseBytes = b.size
+		b.trace.ResponseBody = truncateBody(string(b.head), int(b.size))
+		b.tracer.TraceRequest(b.trace)
+	})
+	return err
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	var headers = make(map[string]string, len(header))
+	for key, values := range header {
+		key = http.CanonicalHeaderKey(key)
+		value := strings.Join(values, ", ")
+		if slices.Contains(secretHeaders, key) {
+			if scheme, _, ok := strings.Cut(value, " "); ok && strings.HasSuffix(key, "Authorization") {
+				value = scheme + " " + redacted
+			} else {
+				value = redacted
+			}
+		}
+		headers[key] = value
+	}
+	return headers
+}
+
+func redactURL(u *url.URL) string {
+	clone := *u
+	if clone.User != nil {
+		clone.User = url.User(clone.User.Username())
+	}
+	if clone.RawQuery == "" {
+		return clone.String()
+	}
+	query := clone.Query()
+	redactValues(query)
+	// Encoding escapes the marker, build the query by hand to keep it readable
+	clone.RawQuery = ""
+	return clone.String() + "?" + formatValues(query)
+}
+
+// redactBody redacts the credentials of the form of a query, and the passwords of the
+// InfluxQL statements it carries
+func redactBody(path, body string) string {
+	if !strings.HasSuffix(path, "/query") {
+		return body
+	}
+	values, err := url.ParseQuery(body)
+	if err != nil {
+		return passwordLiteral.ReplaceAllString(body, "$1'"+redacted+"'")
+	}
+	redactValues(values)
+	return formatValues(values)
+}
+
+func redactValues(values url.Values) {
+	for key, items := range values {
+		for i := range items {
+			switch {
+			case slices.Contains(secretParams, strings.ToLower(key)):
+				items[i] = redacted
+			case key == "q":
+				items[i] = passwordLiteral.ReplaceAllString(items[i], "$1'"+redacted+"'")
+			}
+		}
+	}
+}
+
+// formatValues renders the values unescaped in the order of their keys
+func formatValues(values url.Values) string {
+	var pairs []string
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		for _, value := range values[key] {
+			pairs = append(pairs, key+"="+value)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// truncateBody cuts the head of a body of size bytes to maxTraceBody bytes, binary content
+// and the rune cut in the middle are replaced
+func truncateBody(head string, size int) string {
+	head = head[:min(len(head), maxTraceBody)]
+	body := strings.ToValidUTF8(head, "\uFFFD")
+	if size > len(head) {
+		body += fmt.Sprintf("... (%d bytes truncated)", size-len(head))
+	}
+	return body
+}
+
+// traceRecorder is the RequestTracer of the application, it logs the traces at debug level
+// and keeps the recent ones for the request panel
+type traceRecorder struct {
+	app     *App // The logger of the app is replaced when the data directory moves
+	mu      sync.Mutex
+	entries []*RequestTrace
+	next    int
+	seq     int64
+}
+
+func newTraceRecorder(app *App) *traceRecorder {
+	return &traceRecorder{app: app, entries: make([]*RequestTrace, 0, traceRingSize)}
+}
+
+func (r *traceRecorder) TraceRequest(trace *RequestTrace) {
+	r.mu.Lock()
+	r.seq++
+	trace.Seq = r.seq
+	if len(r.entries) < cap(r.entries) {
+		r.entries = append(r.entries, trace)
+	} else {
+		r.entries[r.next] = trace
+		r.next = (r.next + 1) % len(r.entries)
+	}
+	r.mu.Unlock()
+
+	r.app.logger.Debug("http request", "connection", trace.Connection, "method", trace.Method,
+		"url", trace.URL, "status", trace.Status, "duration_ms", trace.Duration,
+		"request_bytes", trace.RequestBytes, "response_bytes", trace.ResponseBytes,
+		"request_body", trace.RequestBody, "response_body", trace.ResponseBody, "error", trace.Error)
+}
+
+func (r *traceRecorder) list(filter *RequestTraceFilter) *RequestTracePage {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultTraceListLimit
+	}
+	r.mu.Lock()
+	ordered := append(slices.Clone(r.entries[r.next:]), r.entries[:r.next]...)
+	page := &RequestTracePage{Traces: make([]*RequestTrace, 0), LastSeq: r.seq}
+	r.mu.Unlock()
+
+	for _, trace := range ordered {
+		if trace.Seq <= filter.AfterSeq {
+			continue
+		}
+		if filter.Connection != "" && trace.Connection != filter.Connection {
+			continue
+		}
+		if filter.FailedOnly && trace.Error == "" && trace.Status < http.StatusBadRequest {
+			continue
+		}
+		page.Traces = append(page.Traces, trace)
+	}
+	// The newest traces when more match than the limit
+	if len(page.Traces) > limit {
+		page.Traces = page.Traces[len(page.Traces)-limit:]
+	}
+	return page
+}
+
+func (r *traceRecorder) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = r.entries[:0]
+	r.next = 0
+}
+
+// ListRequestTraces returns the traced requests after the AfterSeq of the filter. Requests
+// are traced while the Debug setting is on.
+func (app *App) ListRequestTraces(filter *RequestTraceFilter) (*RequestTracePage, error) {
+	if filter == nil {
+		filter = &RequestTraceFilter{}
+	}
+	return app.traces.list(filter), nil
+}
+
+// ClearRequestTraces empties the request panel, sequence numbers keep growing
+func (app *App) ClearRequestTraces() {
+	app.traces.clear()
+}
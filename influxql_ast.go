@@ -0,0 +1,235 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type StatementCategory string
+
+const (
+	CategoryRead  StatementCategory = "read"
+	CategoryWrite StatementCategory = "write"
+	CategoryDDL   StatementCategory = "ddl"
+	CategoryDCL   StatementCategory = "dcl"
+	// CategoryUnknown is given to statements with syntax errors and to unknown statements,
+	// what they do cannot be told
+	CategoryUnknown StatementCategory = "unknown"
+)
+
+// Statement is a single classified statement of a parsed InfluxQL text
+type Statement struct {
+	Text        string            `json:"text"` // Source text without the trailing ';'
+	Kind        string            `json:"kind"` // Leading keywords, e.g. SELECT, SHOW TAG VALUES, DROP MEASUREMENT
+	Category    StatementCategory `json:"category"`
+	Destructive bool              `json:"destructive"`
+	Start       Position          `json:"start"`
+	End         Position          `json:"end"`
+	Error       *SyntaxError      `json:"error"`
+
+	Tokens    []Token            `json:"-"` // Significant tokens, without whitespace and comments
+	Select    *SelectStatement   `json:"-"` // SELECT and EXPLAIN statements
+	Sources   []*MeasurementRef  `json:"-"` // FROM clause of DELETE, DROP SERIES and SHOW statements
+	Condition Expr               `json:"-"` // WHERE clause of DELETE, DROP SERIES and SHOW statements
+	Insert    *InsertStatement   `json:"-"`
+	Selects   []*SelectStatement `json:"-"` // Every SELECT in the statement, including nested ones
+}
+
+type InsertStatement struct {
+	RetentionPolicy string // From INSERT INTO <rp> <line protocol>
+	LineProtocol    string
+}
+
+type SyntaxError struct {
+	Message string   `json:"message"`
+	Start   Position `json:"start"`
+	End     Position `json:"end"`
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at line %d, column %d: %s", e.Start.Line, e.Start.Column, e.Message)
+}
+
+type ParseResult struct {
+	Statements []*Statement   `json:"statements"`
+	Errors     []*SyntaxError `json:"errors"`
+}
+
+// Err returns the first syntax error of the result, if any
+func (r *ParseResult) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return r.Errors[0]
+}
+
+type SelectStatement struct {
+	Fields     []*SelectField
+	Into       *MeasurementRef
+	Sources    []Source
+	Condition  Expr
+	Dimensions []Expr
+	Fill       string // Raw FILL argument
+	OrderDesc  bool
+	Limit      int
+	Offset     int
+	SLimit     int
+	SOffset    int
+	Location   string // TZ() argument
+	HasLimit   bool
+
+	Start      Position
+	End        Position
+	SourcesEnd Position // End of the FROM clause, where a missing WHERE clause goes
+	LimitPos   *Token   // LIMIT keyword, nil when absent
+}
+
+type SelectField struct {
+	Expr  Expr
+	Alias string
+}
+
+// Source is either a *MeasurementRef or a *SubQuery
+type Source interface {
+	Span() (Position, Position)
+}
+
+type MeasurementRef struct {
+	Database        string
+	RetentionPolicy string
+	Name            string
+	Regex           string
+	IsRegex         bool
+	Backref         bool // Name is :MEASUREMENT, the name of each source measurement
+	Start, EndPos   Position
+}
+
+func (m *MeasurementRef) Span() (Position, Position) { return m.Start, m.EndPos }
+
+type SubQuery struct {
+	Select        *SelectStatement
+	Start, EndPos Position
+}
+
+func (s *SubQuery) Span() (Position, Position) { return s.Start, s.EndPos }
+
+type Expr interface {
+	Span() (Position, Position)
+}
+
+type BinaryExpr struct {
+	Op       string // Upper-case for AND / OR
+	LHS, RHS Expr
+}
+
+func (e *BinaryExpr) Span() (Position, Position) {
+	start, _ := e.LHS.Span()
+	_, end := e.RHS.Span()
+	return start, end
+}
+
+type UnaryExpr struct {
+	Op   string
+	Expr Expr
+	Pos  Position
+}
+
+func (e *UnaryExpr) Span() (Position, Position) {
+	_, end := e.Expr.Span()
+	return e.Pos, end
+}
+
+type ParenExpr struct {
+	Expr          Expr
+	Start, EndPos Position
+}
+
+func (e *ParenExpr) Span() (Position, Position) { return e.Start, e.EndPos }
+
+type Call struct {
+	Name          string // Lower-case function name
+	Args          []Expr
+	Start, EndPos Position
+}
+
+func (e *Call) Span() (Position, Position) { return e.Start, e.EndPos }
+
+type VarRef struct {
+	Name          string
+	Type          string // Cast after ::, e.g. field or tag
+	Start, EndPos Position
+}
+
+func (e *VarRef) Span() (Position, Position) { return e.Start, e.EndPos }
+
+type Wildcard struct {
+	Type          string // Cast after ::, e.g. field or tag
+	Start, EndPos Position
+}
+
+func (e *Wildcard) Span() (Position, Position) { return e.Start, e.EndPos }
+
+type Literal struct {
+	Type          TokenType // TokenString, TokenInteger, TokenNumber, TokenDuration, TokenRegex, TokenBoundParam or TokenKeyword for booleans
+	Value         string
+	Start, EndPos Position
+}
+
+func (e *Literal) Span() (Position, Position) { return e.Start, e.EndPos }
+
+// WalkExpr calls fn for expr and each of its sub-expressions, depth first
+func WalkExpr(expr Expr, fn func(Expr)) {
+	if expr == nil {
+		return
+	}
+	fn(expr)
+	switch e := expr.(type) {
+	case *BinaryExpr:
+		WalkExpr(e.LHS, fn)
+		WalkExpr(e.RHS, fn)
+	case *UnaryExpr:
+		WalkExpr(e.Expr, fn)
+	case *ParenExpr:
+		WalkExpr(e.Expr, fn)
+	case *Call:
+		for _, arg := range e.Args {
+			WalkExpr(arg, fn)
+		}
+	}
+}
+
+// HasTimeCondition reports whether the condition restricts the time column
+func HasTimeCondition(expr Expr) bool {
+	var found bool
+	WalkExpr(expr, func(e Expr) {
+		binary, ok := e.(*BinaryExpr)
+		if !ok {
+			return
+		}
+		switch binary.Op {
+		case "=", "<", "<=", ">", ">=":
+		default:
+			return
+		}
+		for _, side := range []Expr{binary.LHS, binary.RHS} {
+			if ref, ok := side.(*VarRef); ok && strings.EqualFold(ref.Name, "time") {
+				found = true
+			}
+		}
+	})
+	return found
+}
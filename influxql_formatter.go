@@ -0,0 +1,353 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+const formatIndent = "  "
+
+var plainIdentRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// selectClauseStarts begin a new line when they belong to the innermost SELECT
+var selectClauseStarts = map[string]bool{
+	"INTO": true, "FROM": true, "WHERE": true, "GROUP": true, "ORDER": true,
+	"LIMIT": true, "OFFSET": true, "SLIMIT": true, "SOFFSET": true,
+}
+
+// FormatQuery re-emits InfluxQL with upper-case keywords, one clause per line, indented
+// subqueries and conditions, quoted identifiers where required and comments preserved.
+// Text with syntax errors is rejected so that broken queries are never rewritten.
+func (app *App) FormatQuery(text string) (string, error) {
+	parsed := ParseInfluxQL(text)
+	if err := parsed.Err(); err != nil {
+		return "", err
+	}
+	return FormatInfluxQL(text, parsed), nil
+}
+
+type selectContext struct {
+	indent     int
+	parenDepth int
+	clause     string
+}
+
+type formatter struct {
+	out         strings.Builder
+	lineIndent  int
+	lineStart   bool
+	needNewline bool
+	noSpace     bool
+	parenDepth  int
+	contexts    []*selectContext
+	prev        Token
+	identTokens map[int]bool // Offsets of keyword tokens used as identifiers
+}
+
+// FormatInfluxQL formats text using the statements parsed from it
+func FormatInfluxQL(text string, parsed *ParseResult) string {
+	var (
+		f       = &formatter{lineStart: true, identTokens: identifierKeywordOffsets(parsed)}
+		scanner = NewScanner(text)
+		tokens  []Token
+	)
+	for {
+		token := scanner.Scan()
+		if token.Type == TokenEOF {
+			break
+		}
+		if token.Type == TokenWS {
+			continue
+		}
+		tokens = append(tokens, token)
+		if token.IsKeyword("INSERT") && f.statementStart(tokens[:len(tokens)-1]) {
+			tokens = append(tokens, scanner.ScanLineProtocol())
+		}
+	}
+
+	for i, token := range tokens {
+		var next Token
+		for _, candidate := range tokens[i+1:] {
+			if candidate.Type != TokenComment {
+				next = candidate
+				break
+			}
+		}
+		f.format(token, next)
+	}
+	return strings.TrimSpace(f.out.String())
+}
+
+// statementStart reports whether the next token starts a statement
+func (f *formatter) statementStart(previous []Token) bool {
+	for i := len(previous) - 1; i >= 0; i-- {
+		switch previous[i].Type {
+		case TokenComment:
+			continue
+		case TokenSemicolon, TokenLineProtocol:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+func (f *formatter) context() *selectContext {
+	if len(f.contexts) == 0 {
+		return nil
+	}
+	return f.contexts[len(f.contexts)-1]
+}
+
+// atClauseLevel reports whether the current token belongs to the innermost SELECT rather
+// than to a function call or parenthesised expression inside it
+func (f *formatter) atClauseLevel() bool {
+	ctx := f.context()
+	return ctx != nil && ctx.parenDepth == f.parenDepth
+}
+
+func (f *formatter) format(token, next Token) {
+	switch token.Type {
+	case TokenComment:
+		f.formatComment(token)
+		return
+	case TokenSemicolon:
+		if f.needNewline {
+			// The line ends with a -- comment that would swallow the ';'
+			f.newline(0)
+		}
+		f.out.WriteString(";")
+		f.contexts = nil
+		f.parenDepth = 0
+		f.newline(0)
+		f.out.WriteString("\n")
+		f.prev = token
+		return
+	case TokenLineProtocol:
+		f.write(token.Value, true)
+		f.contexts = nil
+		f.newline(0)
+		f.prev = token
+		return
+	}
+
+	if f.needNewline {
+		f.newline(f.lineIndent)
+	}
+
+	switch {
+	case token.IsKeyword("SELECT"):
+		indent := 0
+		if ctx := f.context(); ctx != nil {
+			indent = ctx.indent + 1
+		}
+		if f.prev.Type != 0 && f.prev.Type != TokenSemicolon && f.prev.Type != TokenLineProtocol &&
+			!f.prev.IsKeyword("EXPLAIN") && !f.prev.IsKeyword("ANALYZE") {
+			if f.prev.Type != TokenLParen {
+				indent = f.lineIndent + 1
+			}
+			f.newline(indent)
+		}
+		f.contexts = append(f.contexts, &selectContext{indent: indent, parenDepth: f.parenDepth, clause: "SELECT"})
+	case token.Type == TokenKeyword && selectClauseStarts[token.Value] && f.atClauseLevel():
+		ctx := f.context()
+		ctx.clause = token.Value
+		f.newline(ctx.indent)
+	case (token.IsKeyword("AND") || token.IsKeyword("OR")) && f.atClauseLevel() && f.context().clause == "WHERE":
+		f.newline(f.context().indent + 1)
+	case token.Type == TokenIdent && strings.EqualFold(token.Text, "tz") && next.Type == TokenLParen && f.atClauseLevel():
+		f.newline(f.context().indent)
+	case token.IsKeyword("BEGIN"), token.IsKeyword("END"), token.IsKeyword("DELAY"):
+		for len(f.contexts) > 0 && f.atClauseLevel() {
+			f.contexts = f.contexts[:len(f.contexts)-1]
+		}
+		f.newline(0)
+	case token.Type == TokenRParen && f.atClauseLevel():
+		// Closing parenthesis of a subquery
+		ctx := f.context()
+		f.contexts = f.contexts[:len(f.contexts)-1]
+		f.newline(max(ctx.indent-1, 0))
+	}
+
+	f.write(f.tokenText(token), f.spaceBefore(token))
+	f.noSpace = false
+
+	switch token.Type {
+	case TokenLParen:
+		f.parenDepth++
+		if next.IsKeyword("SELECT") {
+			// The subquery starts on its own line
+			f.lineIndent = f.lineIndent + 1
+		}
+	case TokenRParen:
+		f.parenDepth = max(f.parenDepth-1, 0)
+	case TokenOperator:
+		if (token.Value == "-" || token.Value == "+") && f.isUnaryPosition() {
+			f.noSpace = true
+		}
+	}
+	f.prev = token
+}
+
+func (f *formatter) formatComment(token Token) {
+	text := strings.TrimRight(token.Text, " \t\r")
+	if strings.HasPrefix(text, "--") {
+		if !f.lineStart {
+			f.out.WriteString(" ")
+		}
+		f.out.WriteString(text)
+		f.lineStart = false
+		f.needNewline = true
+		return
+	}
+	f.write(text, true)
+}
+
+func (f *formatter) newline(indent int) {
+	if !f.lineStart {
+		f.out.WriteString("\n")
+	}
+	f.lineIndent = indent
+	f.lineStart = true
+	f.needNewline = false
+}
+
+func (f *formatter) write(text string, space bool) {
+	if f.lineStart {
+		f.out.WriteString(strings.Repeat(formatIndent, f.lineIndent))
+	} else if space {
+		f.out.WriteString(" ")
+	}
+	f.out.WriteString(text)
+	f.lineStart = false
+}
+
+func (f *formatter) tokenText(token Token) string {
+	switch token.Type {
+	case TokenKeyword:
+		if f.identTokens[token.Pos.Offset] {
+			return quoteIdentifier(token.Text)
+		}
+		if f.prev.Type == TokenOperator && f.prev.Value == "::" {
+			// Cast types are kept as written, e.g. value::field
+			return token.Text
+		}
+		return token.Value
+	case TokenIdent:
+		if !plainIdentRegexp.MatchString(token.Text) {
+			return quoteIdentifier(token.Text)
+		}
+	}
+	return token.Text
+}
+
+func (f *formatter) spaceBefore(token Token) bool {
+	if f.noSpace {
+		return false
+	}
+	switch token.Type {
+	case TokenRParen, TokenComma, TokenDot:
+		return false
+	case TokenLParen:
+		// Function calls and keyword arguments such as SAMPLEINTERVAL(...) stay attached to
+		// their name, as written in the source
+		callable := f.prev.Type == TokenIdent || f.prev.Type == TokenKeyword && !structuralKeywords[f.prev.Value]
+		return !callable || token.Pos.Offset != f.prev.End.Offset
+	case TokenOperator:
+		if token.Value == "::" {
+			return false
+		}
+	}
+	switch f.prev.Type {
+	case TokenLParen, TokenDot:
+		return false
+	case TokenOperator:
+		return f.prev.Value != "::"
+	}
+	return true
+}
+
+// isUnaryPosition reports whether a '+' or '-' just written is a sign rather than a
+// binary operator, judged by the token before it
+func (f *formatter) isUnaryPosition() bool {
+	switch f.prev.Type {
+	case 0, TokenOperator, TokenLParen, TokenComma, TokenSemicolon:
+		return true
+	case TokenKeyword:
+		return f.prev.Value != "TRUE" && f.prev.Value != "FALSE" && !f.identTokens[f.prev.Pos.Offset]
+	}
+	return false
+}
+
+// identifierKeywordOffsets finds keyword tokens that the parser accepted as identifiers,
+// such as a field named duration or an alias AS duration, so that the formatter quotes
+// them. The type of a cast such as value::field stays a keyword.
+func identifierKeywordOffsets(parsed *ParseResult) map[int]bool {
+	var offsets = make(map[int]bool)
+	for _, stmt := range parsed.Statements {
+		var spans [][2]Position
+		mark := func(expr Expr) {
+			WalkExpr(expr, func(e Expr) {
+				if ref, ok := e.(*VarRef); ok {
+					// The name is the first token of the reference, the rest is its ::type
+					offsets[ref.Start.Offset] = true
+				}
+			})
+		}
+		markSource := func(source Source) {
+			if ref, ok := source.(*MeasurementRef); ok && ref != nil {
+				spans = append(spans, [2]Position{ref.Start, ref.EndPos})
+			}
+		}
+		for _, selectStmt := range stmt.Selects {
+			for _, field := range selectStmt.Fields {
+				mark(field.Expr)
+			}
+			mark(selectStmt.Condition)
+			for _, dimension := range selectStmt.Dimensions {
+				mark(dimension)
+			}
+			for _, source := range selectStmt.Sources {
+				markSource(source)
+			}
+			if selectStmt.Into != nil {
+				markSource(selectStmt.Into)
+			}
+		}
+		for _, source := range stmt.Sources {
+			markSource(source)
+		}
+		mark(stmt.Condition)
+
+		for i, token := range stmt.Tokens {
+			if token.Type != TokenKeyword {
+				continue
+			}
+			if i > 0 && stmt.Tokens[i-1].IsKeyword("AS") {
+				offsets[token.Pos.Offset] = true
+				continue
+			}
+			for _, span := range spans {
+				if token.Pos.Offset >= span[0].Offset && token.End.Offset <= span[1].Offset {
+					offsets[token.Pos.Offset] = true
+					break
+				}
+			}
+		}
+	}
+	return offsets
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestFormatInfluxQLRoundTrip formats queries and parses the output again, the statements
+// must be the same as those of the original text and formatting must be stable
+func TestFormatInfluxQLRoundTrip(t *testing.T) {
+	var queries = []string{
+		`select mean("value") from cpu where time > now() - 1h and "host" =~ /^web/ group by time(5m), "host" fill(none) order by time desc limit 10`,
+		`SELECT /l/ FROM "h2o_feet" LIMIT 1`,
+		`select "value"::field, "host"::tag from cpu`,
+		`select count(value) as duration from cpu`,
+		`select "duration", "select" from "my measurement" where "from" = 'a'`,
+		`select max(m) from (select mean(v) as m from cpu where (a = 1 or b = 2) and c = 3 group by time(1m)) where m > -1.5`,
+		`select * into "db"."rp".:MEASUREMENT from /.*/ group by *`,
+		`select mean(v) into db.autogen.cpu_1h from cpu group by time(1h) tz('Asia/Shanghai')`,
+		`explain analyze select * from cpu slimit 1 soffset 2`,
+		`create continuous query cq on db resample every 10m for 1h begin select mean(v) into cpu_1h from cpu group by time(1h) end`,
+		`show tag values from cpu with key = "host" where "region" = 'east'`,
+		`delete from cpu where time < '2026-01-01T00:00:00Z'`,
+		`create retention policy rp on db duration 30d replication 1 shard duration 1d default`,
+		"insert cpu,host=a value=1\ncpu,host=b value=2\nselect * from cpu",
+		"select a from m -- trailing comment\n; show measurements",
+		"-- leading comment\nselect * from cpu; /* block */ select * from mem",
+		`select * from cpu where "host" = 'a;b' and time >= now() - 10m`,
+	}
+	for _, query := range queries {
+		formatted, err := (&App{}).FormatQuery(query)
+		if err != nil {
+			t.Errorf("format %q: %v", query, err)
+			continue
+		}
+		want, got := ParseInfluxQL(query), ParseInfluxQL(formatted)
+		if err := got.Err(); err != nil {
+			t.Errorf("format %q: output does not parse: %v\n%s", query, err, formatted)
+			continue
+		}
+		if len(got.Statements) != len(want.Statements) {
+			t.Errorf("format %q: %d statements, want %d\n%s", query, len(got.Statements), len(want.Statements), formatted)
+			continue
+		}
+		for i := range want.Statements {
+			a, b := want.Statements[i], got.Statements[i]
+			if a.Kind != b.Kind || a.Category != b.Category || a.Destructive != b.Destructive {
+				t.Errorf("format %q: statement %d is %s %s, want %s %s", query, i, b.Kind, b.Category, a.Kind, a.Category)
+			}
+			if !slices.Equal(normalizeTokens(a.Tokens), normalizeTokens(b.Tokens)) {
+				t.Errorf("format %q: statement %d tokens differ\nwant %q\ngot  %q", query, i, normalizeTokens(a.Tokens), normalizeTokens(b.Tokens))
+			}
+		}
+		if again := FormatInfluxQL(formatted, got); again != formatted {
+			t.Errorf("format %q is not stable:\n%s\n---\n%s", query, formatted, again)
+		}
+	}
+}
+
+func TestFormatQueryRejectsSyntaxErrors(t *testing.T) {
+	if _, err := (&App{}).FormatQuery(`SELECT * FROM cpu WHERE`); err == nil {
+		t.Error("query with a syntax error was formatted")
+	}
+}
+
+// normalizeTokens maps tokens to values that do not depend on layout, keyword case or
+// identifier quoting
+func normalizeTokens(tokens []Token) []string {
+	values := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		switch token.Type {
+		case TokenKeyword, TokenIdent, TokenQuotedIdent:
+			values = append(values, strings.ToLower(token.Value))
+		case TokenLineProtocol:
+			values = append(values, strings.TrimSpace(token.Text))
+		default:
+			values = append(values, token.Text)
+		}
+	}
+	return values
+}
@@ -0,0 +1,817 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// structuralKeywords may never be used as unquoted identifiers because they start or
+// separate clauses. Other keywords are accepted where an identifier is expected.
+var structuralKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "BY": true, "ORDER": true,
+	"LIMIT": true, "OFFSET": true, "SLIMIT": true, "SOFFSET": true, "INTO": true, "AS": true,
+	"AND": true, "OR": true, "ON": true, "WITH": true, "BEGIN": true, "END": true,
+}
+
+// binaryPrecedence returns the precedence of a binary operator token, or 0
+func binaryPrecedence(token Token) int {
+	switch {
+	case token.IsKeyword("OR"):
+		return 1
+	case token.IsKeyword("AND"):
+		return 2
+	case token.Type != TokenOperator:
+		return 0
+	}
+	switch token.Value {
+	case "=", "!=", "<>", "<", "<=", ">", ">=", "=~", "!~":
+		return 3
+	case "+", "-", "|", "^":
+		return 4
+	case "*", "/", "%", "&":
+		return 5
+	}
+	return 0
+}
+
+// ParseInfluxQL splits text into statements, classifies each statement and reports
+// syntax errors. INSERT statements carry line protocol up to a ';' or a line starting
+// another statement.
+func ParseInfluxQL(text string) *ParseResult {
+	var (
+		result  = &ParseResult{}
+		scanner = NewScanner(text)
+		tokens  []Token
+	)
+	flush := func(end Position) {
+		if len(tokens) == 0 {
+			return
+		}
+		stmt := parseStatement(text, tokens, end)
+		result.Statements = append(result.Statements, stmt)
+		if stmt.Error != nil {
+			result.Errors = append(result.Errors, stmt.Error)
+		}
+		tokens = nil
+	}
+
+	for {
+		token := scanner.Scan()
+		switch token.Type {
+		case TokenWS, TokenComment:
+			continue
+		case TokenEOF:
+			flush(token.Pos)
+			return result
+		case TokenSemicolon:
+			flush(token.Pos)
+			continue
+		case TokenKeyword:
+			if token.Value == "INSERT" && len(tokens) == 0 {
+				stmt := scanInsert(scanner, token)
+				result.Statements = append(result.Statements, stmt)
+				if stmt.Error != nil {
+					result.Errors = append(result.Errors, stmt.Error)
+				}
+				continue
+			}
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+// scanInsert consumes the line protocol after INSERT
+func scanInsert(scanner *Scanner, insert Token) *Statement {
+	line := scanner.ScanLineProtocol()
+	stmt := &Statement{
+		Text:     strings.TrimSpace(insert.Text + line.Text),
+		Kind:     "INSERT",
+		Category: CategoryWrite,
+		Start:    insert.Pos,
+		End:      line.End,
+		Tokens:   []Token{insert, line},
+		Insert:   &InsertStatement{LineProtocol: line.Value},
+	}
+	// INSERT INTO <rp> <line protocol>
+	if fields := strings.Fields(stmt.Insert.LineProtocol); len(fields) > 2 && strings.EqualFold(fields[0], "INTO") {
+		stmt.Insert.RetentionPolicy = fields[1]
+		rest := strings.TrimSpace(stmt.Insert.LineProtocol)
+		rest = strings.TrimSpace(rest[len(fields[0]):])
+		stmt.Insert.LineProtocol = strings.TrimSpace(rest[len(fields[1]):])
+	}
+	if stmt.Insert.LineProtocol == "" {
+		stmt.Error = &SyntaxError{Message: "line protocol required after INSERT", Start: insert.Pos, End: line.End}
+		stmt.Category = CategoryUnknown
+	}
+	return stmt
+}
+
+type parser struct {
+	tokens []Token
+	index  int
+	eof    Token
+	stmt   *Statement
+}
+
+func parseStatement(text string, tokens []Token, end Position) *Statement {
+	var (
+		first = tokens[0]
+		last  = tokens[len(tokens)-1]
+		stmt  = &Statement{
+			Text:   text[first.Pos.Offset:last.End.Offset],
+			Start:  first.Pos,
+			End:    last.End,
+			Tokens: tokens,
+		}
+		p = &parser{tokens: tokens, stmt: stmt, eof: Token{Type: TokenEOF, Pos: end, End: end}}
+	)
+	if err := p.parse(); err != nil {
+		stmt.Error = err
+	}
+	// Scanner errors are more precise than the parser error they cause
+	for _, token := range tokens {
+		if token.Type == TokenIllegal {
+			stmt.Error = &SyntaxError{Message: token.Value, Start: token.Pos, End: token.End}
+			break
+		}
+	}
+	classifyStatement(stmt)
+	return stmt
+}
+
+func (p *parser) peek() Token {
+	if p.index >= len(p.tokens) {
+		return p.eof
+	}
+	return p.tokens[p.index]
+}
+
+func (p *parser) next() Token {
+	token := p.peek()
+	if p.index < len(p.tokens) {
+		p.index++
+	}
+	return token
+}
+
+func (p *parser) prevEnd() Position {
+	if p.index == 0 {
+		return p.eof.Pos
+	}
+	return p.tokens[p.index-1].End
+}
+
+func (p *parser) errorf(token Token, expected string) *SyntaxError {
+	found := token.Text
+	switch token.Type {
+	case TokenEOF:
+		found = "end of statement"
+	case TokenKeyword:
+		found = token.Value
+	}
+	return &SyntaxError{Message: "found " + found + ", expected " + expected, Start: token.Pos, End: token.End}
+}
+
+func (p *parser) expectKeyword(keyword string) *SyntaxError {
+	if token := p.next(); !token.IsKeyword(keyword) {
+		return p.errorf(token, keyword)
+	}
+	return nil
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if p.peek().IsKeyword(keyword) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) parse() *SyntaxError {
+	first := p.peek()
+	if first.Type != TokenKeyword {
+		p.stmt.Kind = strings.ToUpper(first.Text)
+		return p.errorf(first, "SELECT, SHOW, INSERT, DELETE, CREATE, DROP, ALTER, GRANT, REVOKE, SET, KILL or EXPLAIN")
+	}
+
+	switch first.Value {
+	case "SELECT":
+		p.stmt.Kind = "SELECT"
+		return p.parseTopSelect()
+	case "EXPLAIN":
+		p.next()
+		p.stmt.Kind = "EXPLAIN"
+		if p.acceptKeyword("ANALYZE") {
+			p.stmt.Kind = "EXPLAIN ANALYZE"
+		}
+		if !p.peek().IsKeyword("SELECT") {
+			return p.errorf(p.peek(), "SELECT")
+		}
+		return p.parseTopSelect()
+	case "DELETE":
+		p.next()
+		p.stmt.Kind = "DELETE"
+		return p.parseDeleteClauses()
+	case "SHOW":
+		p.next()
+		p.stmt.Kind = "SHOW " + p.kindWords(true)
+		if p.stmt.Kind == "SHOW " {
+			return p.errorf(p.peek(), "object to show")
+		}
+		return p.parseGenericClauses()
+	case "DROP", "CREATE", "ALTER":
+		p.next()
+		words := p.kindWords(false)
+		if words == "" {
+			p.stmt.Kind = first.Value
+			return p.errorf(p.peek(), "object type")
+		}
+		p.stmt.Kind = first.Value + " " + words
+		if p.stmt.Kind == "DROP SERIES" {
+			return p.parseDeleteClauses()
+		}
+		return p.parseGenericClauses()
+	case "SET":
+		p.next()
+		p.stmt.Kind = "SET"
+		if p.peek().IsKeyword("PASSWORD") {
+			p.next()
+			p.stmt.Kind = "SET PASSWORD"
+		}
+		return p.parseGenericClauses()
+	case "KILL":
+		p.next()
+		p.stmt.Kind = "KILL QUERY"
+		if err := p.expectKeyword("QUERY"); err != nil {
+			return err
+		}
+		return p.parseGenericClauses()
+	case "GRANT", "REVOKE":
+		p.next()
+		p.stmt.Kind = first.Value
+		return p.parseGenericClauses()
+	}
+	p.stmt.Kind = first.Value
+	return p.errorf(first, "SELECT, SHOW, INSERT, DELETE, CREATE, DROP, ALTER, GRANT, REVOKE, SET, KILL or EXPLAIN")
+}
+
+// kindWords consumes the words naming the statement object, e.g. "RETENTION POLICY" of
+// DROP RETENTION POLICY. SHOW statements also accept plain identifiers such as SCHEMA.
+func (p *parser) kindWords(allowIdent bool) string {
+	var words []string
+	for {
+		token := p.peek()
+		switch {
+		case token.Type == TokenKeyword && !structuralKeywords[token.Value] && token.Value != "FOR":
+			words = append(words, token.Value)
+		case allowIdent && token.Type == TokenIdent && len(words) == 0:
+			words = append(words, strings.ToUpper(token.Text))
+		default:
+			return strings.Join(words, " ")
+		}
+		p.next()
+		// Only the object type is part of the kind, not the object name
+		if !allowIdent && isObjectTypeEnd(words) {
+			return strings.Join(words, " ")
+		}
+	}
+}
+
+// isObjectTypeEnd reports whether the collected words form a complete object type so that
+// the following (keyword-looking) token is the object name
+func isObjectTypeEnd(words []string) bool {
+	switch strings.Join(words, " ") {
+	case "DATABASE", "MEASUREMENT", "USER", "SUBSCRIPTION", "STREAM", "SHARD",
+		"RETENTION POLICY", "CONTINUOUS QUERY":
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseTopSelect() *SyntaxError {
+	stmt, err := p.parseSelect(false)
+	p.stmt.Select = stmt
+	if err != nil {
+		return err
+	}
+	if token := p.peek(); token.Type != TokenEOF {
+		return p.errorf(token, "end of statement")
+	}
+	return nil
+}
+
+// parseDeleteClauses parses [FROM sources] [WHERE condition] of DELETE and DROP SERIES
+func (p *parser) parseDeleteClauses() *SyntaxError {
+	if p.acceptKeyword("FROM") {
+		sources, err := p.parseMeasurementList()
+		if err != nil {
+			return err
+		}
+		p.stmt.Sources = sources
+	}
+	if p.acceptKeyword("WHERE") {
+		condition, err := p.parseExpr(0)
+		if err != nil {
+			return err
+		}
+		p.stmt.Condition = condition
+	}
+	if token := p.peek(); token.Type != TokenEOF {
+		return p.errorf(token, "FROM, WHERE or end of statement")
+	}
+	return nil
+}
+
+// parseGenericClauses checks the remaining tokens of statements whose grammar is not
+// modelled in detail: parentheses must balance, and FROM, WHERE and nested SELECT
+// clauses are parsed properly.
+func (p *parser) parseGenericClauses() *SyntaxError {
+	var depth []Token
+	for {
+		token := p.peek()
+		switch {
+		case token.Type == TokenEOF:
+			if len(depth) > 0 {
+				return &SyntaxError{Message: "unclosed parenthesis", Start: depth[len(depth)-1].Pos, End: depth[len(depth)-1].End}
+			}
+			return nil
+		case token.Type == TokenLParen:
+			depth = append(depth, token)
+			p.next()
+		case token.Type == TokenRParen:
+			if len(depth) == 0 {
+				return p.errorf(token, "end of statement")
+			}
+			depth = depth[:len(depth)-1]
+			p.next()
+		case token.IsKeyword("SELECT"):
+			if _, err := p.parseSelect(false); err != nil {
+				return err
+			}
+		case token.IsKeyword("FROM") && strings.HasPrefix(p.stmt.Kind, "SHOW"):
+			p.next()
+			sources, err := p.parseMeasurementList()
+			if err != nil {
+				return err
+			}
+			p.stmt.Sources = sources
+		case token.IsKeyword("WHERE"):
+			p.next()
+			condition, err := p.parseExpr(0)
+			if err != nil {
+				return err
+			}
+			p.stmt.Condition = condition
+		default:
+			p.next()
+		}
+	}
+}
+
+// parseSelect parses a SELECT statement, subqueries cannot write INTO a measurement
+func (p *parser) parseSelect(subquery bool) (*SelectStatement, *SyntaxError) {
+	var stmt = &SelectStatement{Start: p.peek().Pos}
+	p.stmt.Selects = append(p.stmt.Selects, stmt)
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return stmt, err
+	}
+
+	for {
+		field := &SelectField{}
+		expr, err := p.parseExpr(0)
+		if err != nil {
+			return stmt, err
+		}
+		field.Expr = expr
+		if p.acceptKeyword("AS") {
+			alias, err := p.parseIdent()
+			if err != nil {
+				return stmt, err
+			}
+			field.Alias = alias
+		}
+		stmt.Fields = append(stmt.Fields, field)
+		if p.peek().Type != TokenComma {
+			break
+		}
+		p.next()
+	}
+
+	if token := p.peek(); subquery && token.IsKeyword("INTO") {
+		return stmt, p.errorf(token, "FROM")
+	}
+	if p.acceptKeyword("INTO") {
+		into, err := p.parseMeasurement(true)
+		if err != nil {
+			return stmt, err
+		}
+		stmt.Into = into
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return stmt, err
+	}
+	for {
+		source, err := p.parseSource()
+		if err != nil {
+			return stmt, err
+		}
+		stmt.Sources = append(stmt.Sources, source)
+		if p.peek().Type != TokenComma {
+			break
+		}
+		p.next()
+	}
+	stmt.SourcesEnd = p.prevEnd()
+
+	if p.acceptKeyword("WHERE") {
+		condition, err := p.parseExpr(0)
+		if err != nil {
+			return stmt, err
+		}
+		stmt.Condition = condition
+	}
+
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return stmt, err
+		}
+		for {
+			dimension, err := p.parseExpr(0)
+			if err != nil {
+				return stmt, err
+			}
+			stmt.Dimensions = append(stmt.Dimensions, dimension)
+			if p.peek().Type != TokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if token := p.peek(); token.Type == TokenIdent && strings.EqualFold(token.Text, "fill") {
+		p.next()
+		if token := p.next(); token.Type != TokenLParen {
+			return stmt, p.errorf(token, "(")
+		}
+		argument := p.next()
+		switch {
+		case argument.Type == TokenIdent, argument.Type == TokenInteger, argument.Type == TokenNumber:
+			stmt.Fill = argument.Text
+		case argument.Type == TokenOperator && argument.Value == "-":
+			number := p.next()
+			if number.Type != TokenInteger && number.Type != TokenNumber {
+				return stmt, p.errorf(number, "number")
+			}
+			stmt.Fill = "-" + number.Text
+		default:
+			return stmt, p.errorf(argument, "fill option or number")
+		}
+		if token := p.next(); token.Type != TokenRParen {
+			return stmt, p.errorf(token, ")")
+		}
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return stmt, err
+		}
+		for {
+			field, err := p.parseIdent()
+			if err != nil {
+				return stmt, err
+			}
+			if !strings.EqualFold(field, "time") {
+				return stmt, &SyntaxError{Message: "only ORDER BY time supported", Start: p.tokens[p.index-1].Pos, End: p.tokens[p.index-1].End}
+			}
+			if p.acceptKeyword("DESC") {
+				stmt.OrderDesc = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			if p.peek().Type != TokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	for _, clause := range []struct {
+		keyword string
+		value   *int
+	}{{"LIMIT", &stmt.Limit}, {"OFFSET", &stmt.Offset}, {"SLIMIT", &stmt.SLimit}, {"SOFFSET", &stmt.SOffset}} {
+		if !p.peek().IsKeyword(clause.keyword) {
+			continue
+		}
+		keyword := p.next()
+		number := p.next()
+		if number.Type != TokenInteger {
+			return stmt, p.errorf(number, "integer")
+		}
+		value, err := strconv.Atoi(number.Text)
+		if err != nil {
+			return stmt, &SyntaxError{Message: "invalid integer " + number.Text, Start: number.Pos, End: number.End}
+		}
+		*clause.value = value
+		if clause.keyword == "LIMIT" {
+			stmt.HasLimit = true
+			stmt.LimitPos = &keyword
+		}
+	}
+
+	if token := p.peek(); token.Type == TokenIdent && strings.EqualFold(token.Text, "tz") {
+		p.next()
+		if token := p.next(); token.Type != TokenLParen {
+			return stmt, p.errorf(token, "(")
+		}
+		location := p.next()
+		if location.Type != TokenString {
+			return stmt, p.errorf(location, "time zone string")
+		}
+		stmt.Location = location.Value
+		if token := p.next(); token.Type != TokenRParen {
+			return stmt, p.errorf(token, ")")
+		}
+	}
+
+	stmt.End = p.prevEnd()
+	return stmt, nil
+}
+
+func (p *parser) parseSource() (Source, *SyntaxError) {
+	if token := p.peek(); token.Type == TokenLParen {
+		p.next()
+		selectStmt, err := p.parseSelect(true)
+		if err != nil {
+			return nil, err
+		}
+		closing := p.next()
+		if closing.Type != TokenRParen {
+			return nil, p.errorf(closing, ")")
+		}
+		return &SubQuery{Select: selectStmt, Start: token.Pos, EndPos: closing.End}, nil
+	}
+	return p.parseMeasurement(false)
+}
+
+func (p *parser) parseMeasurementList() ([]*MeasurementRef, *SyntaxError) {
+	var measurements []*MeasurementRef
+	for {
+		measurement, err := p.parseMeasurement(false)
+		if err != nil {
+			return measurements, err
+		}
+		measurements = append(measurements, measurement)
+		if p.peek().Type != TokenComma {
+			return measurements, nil
+		}
+		p.next()
+	}
+}
+
+// parseMeasurement parses [db.][rp.]name or a regex, "db..name" skips the retention policy.
+// The name of an INTO target may be the :MEASUREMENT backreference.
+func (p *parser) parseMeasurement(into bool) (*MeasurementRef, *SyntaxError) {
+	var (
+		start = p.peek()
+		ref   = &MeasurementRef{Start: start.Pos}
+		parts []string
+	)
+	for {
+		token := p.peek()
+		switch {
+		case token.Type == TokenRegex && !into:
+			p.next()
+			ref.IsRegex = true
+			ref.Regex = token.Value
+		case token.Type == TokenBackref && into:
+			p.next()
+			ref.Backref = true
+			parts = append(parts, token.Value)
+		case p.isIdentToken(token):
+			p.next()
+			if token.Type == TokenQuotedIdent {
+				parts = append(parts, token.Value)
+			} else {
+				parts = append(parts, token.Text)
+			}
+		case token.Type == TokenDot && len(parts) > 0:
+			// Empty retention policy in db..name
+			parts = append(parts, "")
+		default:
+			return ref, p.errorf(token, "measurement")
+		}
+		if ref.IsRegex || ref.Backref || p.peek().Type != TokenDot || len(parts) >= 3 {
+			break
+		}
+		p.next()
+	}
+	ref.EndPos = p.prevEnd()
+	switch len(parts) {
+	case 1:
+		ref.Name = parts[0]
+	case 2:
+		ref.RetentionPolicy, ref.Name = parts[0], parts[1]
+	case 3:
+		ref.Database, ref.RetentionPolicy, ref.Name = parts[0], parts[1], parts[2]
+	}
+	return ref, nil
+}
+
+func (p *parser) isIdentToken(token Token) bool {
+	return token.IsIdent() || token.Type == TokenKeyword && !structuralKeywords[token.Value]
+}
+
+func (p *parser) parseIdent() (string, *SyntaxError) {
+	token := p.next()
+	if !p.isIdentToken(token) {
+		return "", p.errorf(token, "identifier")
+	}
+	if token.Type == TokenQuotedIdent {
+		return token.Value, nil
+	}
+	return token.Text, nil
+}
+
+func (p *parser) parseExpr(minPrecedence int) (Expr, *SyntaxError) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator := p.peek()
+		precedence := binaryPrecedence(operator)
+		if precedence == 0 || precedence <= minPrecedence {
+			return lhs, nil
+		}
+		p.next()
+		rhs, err := p.parseExpr(precedence)
+		if err != nil {
+			return nil, err
+		}
+		lhs = &BinaryExpr{Op: operator.Value, LHS: lhs, RHS: rhs}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, *SyntaxError) {
+	if token := p.peek(); token.Type == TokenOperator && (token.Value == "-" || token.Value == "+") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: token.Value, Expr: expr, Pos: token.Pos}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, *SyntaxError) {
+	token := p.next()
+	switch {
+	case token.Type == TokenLParen:
+		expr, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		closing := p.next()
+		if closing.Type != TokenRParen {
+			return nil, p.errorf(closing, ")")
+		}
+		return &ParenExpr{Expr: expr, Start: token.Pos, EndPos: closing.End}, nil
+	case token.Type == TokenString, token.Type == TokenInteger, token.Type == TokenNumber,
+		token.Type == TokenDuration, token.Type == TokenRegex, token.Type == TokenBoundParam:
+		return &Literal{Type: token.Type, Value: token.Value, Start: token.Pos, EndPos: token.End}, nil
+	case token.IsKeyword("TRUE"), token.IsKeyword("FALSE"):
+		return &Literal{Type: TokenKeyword, Value: token.Value, Start: token.Pos, EndPos: token.End}, nil
+	case token.Type == TokenOperator && token.Value == "*":
+		wildcard := &Wildcard{Start: token.Pos, EndPos: token.End}
+		if cast := p.peek(); cast.Type == TokenOperator && cast.Value == "::" {
+			p.next()
+			typ, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			wildcard.Type = strings.ToLower(typ)
+			wildcard.EndPos = p.prevEnd()
+		}
+		return wildcard, nil
+	case token.IsKeyword("DISTINCT"):
+		// DISTINCT field is shorthand for DISTINCT(field)
+		if p.peek().Type != TokenLParen {
+			arg, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			_, end := arg.Span()
+			return &Call{Name: "distinct", Args: []Expr{arg}, Start: token.Pos, EndPos: end}, nil
+		}
+		return p.parseCall(token)
+	case p.isIdentToken(token):
+		if token.Type != TokenQuotedIdent && p.peek().Type == TokenLParen {
+			return p.parseCall(token)
+		}
+		name := token.Text
+		if token.Type == TokenQuotedIdent {
+			name = token.Value
+		}
+		ref := &VarRef{Name: name, Start: token.Pos, EndPos: token.End}
+		if cast := p.peek(); cast.Type == TokenOperator && cast.Value == "::" {
+			p.next()
+			typ, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			ref.Type = strings.ToLower(typ)
+			ref.EndPos = p.prevEnd()
+		}
+		return ref, nil
+	}
+	return nil, p.errorf(token, "expression")
+}
+
+func (p *parser) parseCall(name Token) (Expr, *SyntaxError) {
+	var call = &Call{Name: strings.ToLower(name.Text), Start: name.Pos}
+	p.next() // (
+	if closing := p.peek(); closing.Type == TokenRParen {
+		p.next()
+		call.EndPos = closing.End
+		return call, nil
+	}
+	for {
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+		token := p.next()
+		switch token.Type {
+		case TokenComma:
+			continue
+		case TokenRParen:
+			call.EndPos = token.End
+			return call, nil
+		}
+		return nil, p.errorf(token, ", or )")
+	}
+}
+
+// classifyStatement sets the category and destructive flag from the statement kind. The
+// parser stops at the first error, so the kind of a statement with an error may miss the
+// clause deciding it, e.g. an INTO target, and its category is unknown.
+func classifyStatement(stmt *Statement) {
+	kind := stmt.Kind
+	verb, object, _ := strings.Cut(kind, " ")
+	switch verb {
+	case "SELECT":
+		stmt.Category = CategoryRead
+		if stmt.Select != nil && stmt.Select.Into != nil {
+			stmt.Category = CategoryWrite
+		}
+	case "EXPLAIN", "SHOW":
+		stmt.Category = CategoryRead
+		// EXPLAIN ANALYZE executes the query, including its INTO clause
+		if kind == "EXPLAIN ANALYZE" && stmt.Select != nil && stmt.Select.Into != nil {
+			stmt.Category = CategoryWrite
+		}
+	case "INSERT":
+		stmt.Category = CategoryWrite
+	case "DELETE":
+		stmt.Category = CategoryWrite
+		stmt.Destructive = true
+	case "GRANT", "SET", "KILL":
+		stmt.Category = CategoryDCL
+	case "REVOKE":
+		stmt.Category = CategoryDCL
+		stmt.Destructive = true
+	case "CREATE", "DROP", "ALTER":
+		stmt.Category = CategoryDDL
+		if object == "USER" {
+			stmt.Category = CategoryDCL
+		}
+		if object == "SERIES" {
+			stmt.Category = CategoryWrite
+		}
+		stmt.Destructive = verb == "DROP" || kind == "ALTER RETENTION POLICY"
+	default:
+		// Unknown statements are treated as the most dangerous kind
+		stmt.Category = CategoryUnknown
+		stmt.Destructive = true
+	}
+	if stmt.Error != nil {
+		stmt.Category = CategoryUnknown
+	}
+}
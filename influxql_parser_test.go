@@ -0,0 +1,176 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+type classified struct {
+	kind        string
+	category    StatementCategory
+	destructive bool
+	hasError    bool
+}
+
+func TestParseInfluxQLClassification(t *testing.T) {
+	var tests = []struct {
+		name  string
+		query string
+		want  []classified
+	}{
+		{"select", `SELECT mean("value") FROM cpu WHERE time > now() - 1h GROUP BY time(5m)`,
+			[]classified{{"SELECT", CategoryRead, false, false}}},
+		{"select regex field", `SELECT /l/ FROM "h2o_feet" LIMIT 1`,
+			[]classified{{"SELECT", CategoryRead, false, false}}},
+		{"select subquery", `SELECT max(m) FROM (SELECT mean(v) AS m FROM cpu GROUP BY time(1m))`,
+			[]classified{{"SELECT", CategoryRead, false, false}}},
+		{"select into", `SELECT mean(v) INTO cpu_1h FROM cpu GROUP BY time(1h)`,
+			[]classified{{"SELECT", CategoryWrite, false, false}}},
+		{"select into backref", `SELECT * INTO "db"."rp".:MEASUREMENT FROM /.*/ GROUP BY *`,
+			[]classified{{"SELECT", CategoryWrite, false, false}}},
+		{"show", `SHOW TAG VALUES FROM cpu WITH KEY = "host"`,
+			[]classified{{"SHOW TAG VALUES", CategoryRead, false, false}}},
+		{"explain", `EXPLAIN SELECT * INTO t FROM cpu`,
+			[]classified{{"EXPLAIN", CategoryRead, false, false}}},
+		{"explain analyze", `EXPLAIN ANALYZE SELECT * FROM cpu`,
+			[]classified{{"EXPLAIN ANALYZE", CategoryRead, false, false}}},
+		{"explain analyze into", `EXPLAIN ANALYZE SELECT * INTO t FROM cpu`,
+			[]classified{{"EXPLAIN ANALYZE", CategoryWrite, false, false}}},
+		{"insert", `INSERT cpu,host=a value=1`,
+			[]classified{{"INSERT", CategoryWrite, false, false}}},
+		{"delete", `DELETE FROM cpu WHERE time < now() - 30d`,
+			[]classified{{"DELETE", CategoryWrite, true, false}}},
+		{"drop series", `DROP SERIES FROM cpu WHERE "host" = 'a'`,
+			[]classified{{"DROP SERIES", CategoryWrite, true, false}}},
+		{"drop measurement", `DROP MEASUREMENT cpu`,
+			[]classified{{"DROP MEASUREMENT", CategoryDDL, true, false}}},
+		{"create database", `CREATE DATABASE db`,
+			[]classified{{"CREATE DATABASE", CategoryDDL, false, false}}},
+		{"create user", `CREATE USER u WITH PASSWORD 'secret'`,
+			[]classified{{"CREATE USER", CategoryDCL, false, false}}},
+		{"revoke", `REVOKE ALL PRIVILEGES FROM u`,
+			[]classified{{"REVOKE", CategoryDCL, true, false}}},
+		{"multiple statements", "SELECT * FROM cpu; DROP DATABASE db;\n-- done\nSHOW DATABASES",
+			[]classified{
+				{"SELECT", CategoryRead, false, false},
+				{"DROP DATABASE", CategoryDDL, true, false},
+				{"SHOW DATABASES", CategoryRead, false, false},
+			}},
+		{"multi-line insert", "INSERT cpu value=1\ncpu value=2\nSELECT * FROM cpu",
+			[]classified{
+				{"INSERT", CategoryWrite, false, false},
+				{"SELECT", CategoryRead, false, false},
+			}},
+		{"semicolon in string", `SELECT * FROM cpu WHERE "host" = 'a;b'; SHOW MEASUREMENTS`,
+			[]classified{
+				{"SELECT", CategoryRead, false, false},
+				{"SHOW MEASUREMENTS", CategoryRead, false, false},
+			}},
+		{"incomplete condition", `SELECT mean(v) FROM cpu WHERE`,
+			[]classified{{"SELECT", CategoryUnknown, false, true}}},
+		{"misspelled backref", `SELECT * INTO "db".:MEASUREMENTS FROM cpu`,
+			[]classified{{"SELECT", CategoryUnknown, false, true}}},
+		{"regex into", `SELECT * INTO /t/ FROM cpu`,
+			[]classified{{"SELECT", CategoryUnknown, false, true}}},
+		{"subquery into", `SELECT max(m) FROM (SELECT mean(v) AS m INTO t FROM cpu)`,
+			[]classified{{"SELECT", CategoryUnknown, false, true}}},
+		{"unknown statement", `FOO BAR`,
+			[]classified{{"FOO", CategoryUnknown, true, true}}},
+		{"error between statements", "SELECT * FROM cpu; SELECT FROM; DROP MEASUREMENT cpu",
+			[]classified{
+				{"SELECT", CategoryRead, false, false},
+				{"SELECT", CategoryUnknown, false, true},
+				{"DROP MEASUREMENT", CategoryDDL, true, false},
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := ParseInfluxQL(tt.query)
+			if len(parsed.Statements) != len(tt.want) {
+				t.Fatalf("%d statements, want %d: %v", len(parsed.Statements), len(tt.want), statementTexts(parsed))
+			}
+			for i, stmt := range parsed.Statements {
+				got := classified{stmt.Kind, stmt.Category, stmt.Destructive, stmt.Error != nil}
+				if got != tt.want[i] {
+					t.Errorf("statement %d %q = %+v, want %+v (error %v)", i, stmt.Text, got, tt.want[i], stmt.Error)
+				}
+			}
+			if hasError := parsed.Err() != nil; hasError != (len(parsed.Errors) > 0) {
+				t.Errorf("Err() = %v with %d errors", parsed.Err(), len(parsed.Errors))
+			}
+		})
+	}
+}
+
+// TestParseInfluxQLPositions checks the statement boundaries and the position of a syntax
+// error, which the editor uses to mark it
+func TestParseInfluxQLPositions(t *testing.T) {
+	query := "SELECT * FROM cpu;\nSELECT *\nFROM cpu WHERE"
+	parsed := ParseInfluxQL(query)
+	if len(parsed.Statements) != 2 {
+		t.Fatalf("%d statements, want 2", len(parsed.Statements))
+	}
+	first, second := parsed.Statements[0], parsed.Statements[1]
+	if first.Text != "SELECT * FROM cpu" {
+		t.Errorf("first statement text = %q", first.Text)
+	}
+	if second.Text != "SELECT *\nFROM cpu WHERE" || second.Start.Line != 2 {
+		t.Errorf("second statement = %q at line %d, want line 2", second.Text, second.Start.Line)
+	}
+	if query[second.Start.Offset:second.End.Offset] != second.Text {
+		t.Errorf("second statement offsets %d-%d do not match its text", second.Start.Offset, second.End.Offset)
+	}
+	if second.Error == nil || second.Error.Start.Line != 3 {
+		t.Fatalf("second statement error = %v, want an error on line 3", second.Error)
+	}
+}
+
+func TestParseInfluxQLInto(t *testing.T) {
+	var tests = []struct {
+		query    string
+		database string
+		name     string
+		backref  bool
+	}{
+		{`SELECT * INTO cpu_copy FROM cpu`, "", "cpu_copy", false},
+		{`SELECT * INTO "db"."rp"."cpu.copy" FROM cpu`, "db", "cpu.copy", false},
+		{`SELECT * INTO "db"."rp".:MEASUREMENT FROM /.*/`, "db", ":MEASUREMENT", true},
+		{`SELECT * INTO db..:measurement FROM cpu`, "db", ":MEASUREMENT", true},
+	}
+	for _, tt := range tests {
+		parsed := ParseInfluxQL(tt.query)
+		if err := parsed.Err(); err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		into := parsed.Statements[0].Select.Into
+		if into == nil {
+			t.Errorf("%s: INTO target missing", tt.query)
+			continue
+		}
+		if into.Database != tt.database || into.Name != tt.name || into.Backref != tt.backref {
+			t.Errorf("%s: INTO = %+v, want database %q, name %q, backref %v", tt.query, into, tt.database, tt.name, tt.backref)
+		}
+	}
+}
+
+func statementTexts(parsed *ParseResult) []string {
+	texts := make([]string, 0, len(parsed.Statements))
+	for _, stmt := range parsed.Statements {
+		texts = append(texts, stmt.Text)
+	}
+	return texts
+}
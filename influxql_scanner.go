@@ -0,0 +1,512 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type TokenType int
+
+const (
+	TokenIllegal TokenType = iota
+	TokenEOF
+	TokenWS
+	TokenComment
+	TokenIdent       // unquoted identifier
+	TokenQuotedIdent // "quoted identifier"
+	TokenKeyword
+	TokenString   // 'string literal'
+	TokenInteger  // 10
+	TokenNumber   // 10.5
+	TokenDuration // 10m, 1h30m
+	TokenRegex    // /regex/
+	TokenBoundParam
+	TokenOperator // + - * / % & | ^ = != <> < <= > >= =~ !~ ::
+	TokenLParen
+	TokenRParen
+	TokenComma
+	TokenDot
+	TokenSemicolon
+	TokenLineProtocol // Line protocol after INSERT
+	TokenBackref      // :MEASUREMENT of an INTO target, the name of each source measurement
+)
+
+var tokenTypeNames = map[TokenType]string{
+	TokenIllegal:      "illegal",
+	TokenEOF:          "EOF",
+	TokenWS:           "whitespace",
+	TokenComment:      "comment",
+	TokenIdent:        "identifier",
+	TokenQuotedIdent:  "identifier",
+	TokenKeyword:      "keyword",
+	TokenString:       "string",
+	TokenInteger:      "integer",
+	TokenNumber:       "number",
+	TokenDuration:     "duration",
+	TokenRegex:        "regex",
+	TokenBoundParam:   "bound parameter",
+	TokenOperator:     "operator",
+	TokenLParen:       "(",
+	TokenRParen:       ")",
+	TokenComma:        ",",
+	TokenDot:          ".",
+	TokenSemicolon:    ";",
+	TokenLineProtocol: "line protocol",
+	TokenBackref:      ":MEASUREMENT",
+}
+
+func (t TokenType) String() string {
+	return tokenTypeNames[t]
+}
+
+// Position locates a token in the source text. Line and Column are 1-based, Column
+// counts characters rather than bytes.
+type Position struct {
+	Offset int `json:"offset"` // Byte offset
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type Token struct {
+	Type  TokenType
+	Text  string // Source text of the token
+	Value string // Unquoted and unescaped value for identifiers, strings and regexes
+	Pos   Position
+	End   Position
+}
+
+// IsKeyword reports whether the token is the given (upper-case) keyword
+func (t Token) IsKeyword(keyword string) bool {
+	return t.Type == TokenKeyword && t.Value == keyword
+}
+
+// IsIdent reports whether the token is an identifier, quoted or not
+func (t Token) IsIdent() bool {
+	return t.Type == TokenIdent || t.Type == TokenQuotedIdent
+}
+
+var influxqlKeywords = map[string]struct{}{}
+
+func init() {
+	for _, keyword := range strings.Fields(`ALL ALTER ANALYZE AND ANY AS ASC BEGIN BY CARDINALITY CONTINUOUS CREATE
+		DATABASE DATABASES DEFAULT DELAY DELETE DESC DESTINATIONS DIAGNOSTICS DISTINCT DOWNSAMPLE DOWNSAMPLES
+		DROP DURATION END ENGINETYPE EVERY EXACT EXPLAIN FIELD FOR FROM GRANT GRANTS GROUP GROUPS IN INF INSERT
+		INTO KEY KEYS KILL LIMIT MEASUREMENT MEASUREMENTS NAME OFFSET ON OR ORDER PASSWORD POLICIES POLICY
+		PRIMARYKEY PRIVILEGES QUERIES QUERY READ REPLICATION RESAMPLE RETENTION REVOKE SELECT SERIES SET
+		SHARD SHARDKEY SHARDS SHARDGROUP SHOW SLIMIT SOFFSET SORTKEY STATS STREAM STREAMS SUBSCRIPTION
+		SUBSCRIPTIONS TAG TO USER USERS VALUES WHERE WITH WRITE SAMPLEINTERVAL TIMEINTERVAL TRUE FALSE`) {
+		influxqlKeywords[keyword] = struct{}{}
+	}
+}
+
+// statementStarts are the keywords starting a statement, a line starting with one ends the line protocol of INSERT
+var statementStarts = map[string]bool{
+	"SELECT": true, "SHOW": true, "INSERT": true, "DELETE": true, "CREATE": true, "DROP": true,
+	"ALTER": true, "GRANT": true, "REVOKE": true, "SET": true, "KILL": true, "EXPLAIN": true,
+}
+
+// isKeyword reports whether the word is reserved by InfluxQL
+func isKeyword(word string) bool {
+	_, ok := influxqlKeywords[strings.ToUpper(word)]
+	return ok
+}
+
+// Scanner splits InfluxQL text into tokens. Regular expressions are only recognised
+// where InfluxQL allows them (after =~, !~, in field lists and in FROM / GROUP BY lists),
+// otherwise '/' is the division operator.
+type Scanner struct {
+	src    string
+	offset int
+	line   int
+	column int
+
+	prev      Token // Previous significant token
+	regexList bool  // Inside a FROM or GROUP BY list where regexes are allowed
+}
+
+func NewScanner(src string) *Scanner {
+	return &Scanner{src: src, line: 1, column: 1}
+}
+
+// ScanAll returns every token of the source including whitespace and comments, the last
+// token is always TokenEOF
+func (s *Scanner) ScanAll() []Token {
+	var tokens []Token
+	for {
+		token := s.Scan()
+		tokens = append(tokens, token)
+		if token.Type == TokenEOF {
+			return tokens
+		}
+	}
+}
+
+func (s *Scanner) Scan() Token {
+	token := s.scan()
+	switch token.Type {
+	case TokenWS, TokenComment:
+		return token
+	case TokenKeyword:
+		switch token.Value {
+		case "FROM", "BY":
+			s.regexList = true
+		case "WHERE", "LIMIT", "OFFSET", "SLIMIT", "SOFFSET", "ORDER", "INTO", "SELECT", "ON":
+			s.regexList = false
+		}
+	case TokenSemicolon:
+		s.regexList = false
+	}
+	s.prev = token
+	return token
+}
+
+// ScanLineProtocol returns the line protocol following INSERT as a single token. INSERT is
+// followed by line protocol rather than InfluxQL, so callers switch to this after INSERT.
+// The token extends over the following lines up to a ';' outside a string field, or up to
+// a line starting with a statement keyword.
+func (s *Scanner) ScanLineProtocol() Token {
+	var (
+		start  = s.pos()
+		quoted bool
+	)
+	for s.offset < len(s.src) {
+		switch c := s.peek(0); {
+		case c == '\\' && quoted:
+			s.next()
+		case c == '"':
+			quoted = !quoted
+		case c == ';' && !quoted:
+			return s.token(TokenLineProtocol, start, strings.TrimSpace(s.src[start.Offset:s.offset]))
+		case c == '\n' && s.startsStatement(s.offset+1):
+			return s.token(TokenLineProtocol, start, strings.TrimSpace(s.src[start.Offset:s.offset]))
+		}
+		s.next()
+	}
+	return s.token(TokenLineProtocol, start, strings.TrimSpace(s.src[start.Offset:s.offset]))
+}
+
+// startsStatement reports whether the line at offset starts with a statement keyword
+func (s *Scanner) startsStatement(offset int) bool {
+	line := strings.TrimLeft(s.src[offset:], " \t\r")
+	end := strings.IndexFunc(line, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end == -1 {
+		end = len(line)
+	}
+	return statementStarts[strings.ToUpper(line[:end])]
+}
+
+func (s *Scanner) pos() Position {
+	return Position{Offset: s.offset, Line: s.line, Column: s.column}
+}
+
+func (s *Scanner) peek(n int) byte {
+	if s.offset+n >= len(s.src) {
+		return 0
+	}
+	return s.src[s.offset+n]
+}
+
+func (s *Scanner) next() rune {
+	if s.offset >= len(s.src) {
+		return 0
+	}
+	r, size := utf8.DecodeRuneInString(s.src[s.offset:])
+	s.offset += size
+	if r == '\n' {
+		s.line++
+		s.column = 1
+	} else {
+		s.column++
+	}
+	return r
+}
+
+func (s *Scanner) token(typ TokenType, start Position, value string) Token {
+	return Token{Type: typ, Text: s.src[start.Offset:s.offset], Value: value, Pos: start, End: s.pos()}
+}
+
+func (s *Scanner) scan() Token {
+	start := s.pos()
+	if s.offset >= len(s.src) {
+		return Token{Type: TokenEOF, Pos: start, End: start}
+	}
+
+	c := s.peek(0)
+	switch {
+	case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+		for c := s.peek(0); c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'; c = s.peek(0) {
+			s.next()
+		}
+		return s.token(TokenWS, start, "")
+	case c == '-' && s.peek(1) == '-':
+		for s.offset < len(s.src) && s.peek(0) != '\n' {
+			s.next()
+		}
+		return s.token(TokenComment, start, "")
+	case c == '/' && s.peek(1) == '*':
+		s.next()
+		s.next()
+		for s.offset < len(s.src) && !(s.peek(0) == '*' && s.peek(1) == '/') {
+			s.next()
+		}
+		if s.offset >= len(s.src) {
+			return s.token(TokenIllegal, start, "unterminated comment")
+		}
+		s.next()
+		s.next()
+		return s.token(TokenComment, start, "")
+	case c == '\'':
+		value, ok := s.scanQuoted('\'')
+		if !ok {
+			return s.token(TokenIllegal, start, "unterminated string")
+		}
+		return s.token(TokenString, start, value)
+	case c == '"':
+		value, ok := s.scanQuoted('"')
+		if !ok {
+			return s.token(TokenIllegal, start, "unterminated quoted identifier")
+		}
+		return s.token(TokenQuotedIdent, start, value)
+	case c == '/' && s.regexAllowed():
+		value, ok := s.scanQuoted('/')
+		if !ok {
+			return s.token(TokenIllegal, start, "unterminated regex")
+		}
+		return s.token(TokenRegex, start, value)
+	case c == '$':
+		s.next()
+		for isWordByte(s.peek(0)) {
+			s.next()
+		}
+		if s.offset-start.Offset == 1 {
+			return s.token(TokenIllegal, start, "bound parameter name required")
+		}
+		return s.token(TokenBoundParam, start, s.src[start.Offset+1:s.offset])
+	case c >= '0' && c <= '9' || c == '.' && s.peek(1) >= '0' && s.peek(1) <= '9':
+		return s.scanNumber(start)
+	case isIdentStart(c):
+		for isWordByte(s.peek(0)) {
+			s.next()
+		}
+		word := s.src[start.Offset:s.offset]
+		if upper := strings.ToUpper(word); isKeyword(upper) {
+			return s.token(TokenKeyword, start, upper)
+		}
+		return s.token(TokenIdent, start, word)
+	}
+
+	switch c {
+	case '(':
+		s.next()
+		return s.token(TokenLParen, start, "(")
+	case ')':
+		s.next()
+		return s.token(TokenRParen, start, ")")
+	case ',':
+		s.next()
+		return s.token(TokenComma, start, ",")
+	case ';':
+		s.next()
+		return s.token(TokenSemicolon, start, ";")
+	case '.':
+		s.next()
+		return s.token(TokenDot, start, ".")
+	case '+', '-', '*', '/', '%', '&', '|', '^':
+		s.next()
+		return s.token(TokenOperator, start, string(c))
+	case '=':
+		s.next()
+		if s.peek(0) == '~' {
+			s.next()
+			return s.token(TokenOperator, start, "=~")
+		}
+		return s.token(TokenOperator, start, "=")
+	case '!':
+		s.next()
+		if s.peek(0) == '=' || s.peek(0) == '~' {
+			s.next()
+			return s.token(TokenOperator, start, s.src[start.Offset:s.offset])
+		}
+		return s.token(TokenIllegal, start, "unexpected '!'")
+	case '<':
+		s.next()
+		if s.peek(0) == '=' || s.peek(0) == '>' {
+			s.next()
+		}
+		return s.token(TokenOperator, start, s.src[start.Offset:s.offset])
+	case '>':
+		s.next()
+		if s.peek(0) == '=' {
+			s.next()
+		}
+		return s.token(TokenOperator, start, s.src[start.Offset:s.offset])
+	case ':':
+		s.next()
+		if s.peek(0) == ':' {
+			s.next()
+			return s.token(TokenOperator, start, "::")
+		}
+		const backref = "MEASUREMENT"
+		if word := s.src[s.offset:min(s.offset+len(backref), len(s.src))]; strings.EqualFold(word, backref) && !isWordByte(s.peek(len(backref))) {
+			for range backref {
+				s.next()
+			}
+			return s.token(TokenBackref, start, ":"+backref)
+		}
+		return s.token(TokenIllegal, start, "unexpected ':'")
+	}
+
+	r := s.next()
+	if unicode.IsLetter(r) {
+		// Identifiers with non-ASCII letters
+		for s.offset < len(s.src) {
+			r, _ := utf8.DecodeRuneInString(s.src[s.offset:])
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+				break
+			}
+			s.next()
+		}
+		return s.token(TokenIdent, start, s.src[start.Offset:s.offset])
+	}
+	return s.token(TokenIllegal, start, "unexpected character "+string(r))
+}
+
+// regexAllowed reports whether a '/' at the current position starts a regex
+func (s *Scanner) regexAllowed() bool {
+	switch s.prev.Type {
+	case TokenOperator:
+		return s.prev.Value == "=~" || s.prev.Value == "!~"
+	case TokenKeyword:
+		return s.prev.Value == "SELECT" || s.prev.Value == "FROM" || s.prev.Value == "BY" && s.regexList
+	case TokenComma:
+		// Nothing can be divided after a comma, "SELECT a, /b/" selects the fields matching b
+		return true
+	}
+	return false
+}
+
+// scanQuoted consumes a quoted literal and returns its unescaped value
+func (s *Scanner) scanQuoted(quote byte) (string, bool) {
+	var value strings.Builder
+	s.next()
+	for s.offset < len(s.src) {
+		c := s.peek(0)
+		if c == quote {
+			s.next()
+			return value.String(), true
+		}
+		if c == '\n' && quote != '\'' {
+			return value.String(), false
+		}
+		if c == '\\' && s.offset+1 < len(s.src) {
+			escaped := s.peek(1)
+			switch {
+			case escaped == quote || escaped == '\\' && quote != '/':
+				s.next()
+				s.next()
+				value.WriteByte(escaped)
+				continue
+			case escaped == 'n' && quote == '\'':
+				s.next()
+				s.next()
+				value.WriteByte('\n')
+				continue
+			}
+		}
+		value.WriteRune(s.next())
+	}
+	return value.String(), false
+}
+
+func (s *Scanner) scanNumber(start Position) Token {
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	for isDigit(s.peek(0)) {
+		s.next()
+	}
+	typ := TokenInteger
+	if s.peek(0) == '.' && isDigit(s.peek(1)) || s.peek(0) == '.' && s.offset == start.Offset {
+		typ = TokenNumber
+		s.next()
+		for isDigit(s.peek(0)) {
+			s.next()
+		}
+	}
+	if (s.peek(0) == 'e' || s.peek(0) == 'E') && (isDigit(s.peek(1)) || (s.peek(1) == '+' || s.peek(1) == '-') && isDigit(s.peek(2))) {
+		typ = TokenNumber
+		s.next()
+		s.next()
+		for isDigit(s.peek(0)) {
+			s.next()
+		}
+	}
+	if typ == TokenInteger && isDurationUnitStart(s.src[s.offset:]) {
+		// Durations such as 10s, 1h30m and 500ms
+		for {
+			unit := durationUnit(s.src[s.offset:])
+			if unit == "" {
+				break
+			}
+			for range unit {
+				s.next()
+			}
+			if !isDigit(s.peek(0)) {
+				break
+			}
+			for isDigit(s.peek(0)) {
+				s.next()
+			}
+		}
+		if isWordByte(s.peek(0)) {
+			for isWordByte(s.peek(0)) {
+				s.next()
+			}
+			return s.token(TokenIllegal, start, "invalid duration "+s.src[start.Offset:s.offset])
+		}
+		return s.token(TokenDuration, start, s.src[start.Offset:s.offset])
+	}
+	if isIdentStart(s.peek(0)) {
+		for isWordByte(s.peek(0)) {
+			s.next()
+		}
+		return s.token(TokenIllegal, start, "invalid number "+s.src[start.Offset:s.offset])
+	}
+	return s.token(typ, start, s.src[start.Offset:s.offset])
+}
+
+var durationUnits = []string{"ns", "us", "µs", "ms", "s", "m", "h", "d", "w", "u"}
+
+func durationUnit(rest string) string {
+	for _, unit := range durationUnits {
+		if strings.HasPrefix(rest, unit) {
+			return unit
+		}
+	}
+	return ""
+}
+
+func isDurationUnitStart(rest string) bool {
+	return durationUnit(rest) != ""
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
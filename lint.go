@@ -0,0 +1,275 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+const (
+	LintSyntax               = "syntax"
+	LintSelectStarNoTime     = "select-star-without-time"
+	LintRawNoLimit           = "raw-query-without-limit"
+	LintGroupByStar          = "group-by-star"
+	LintRegexHighCardinality = "regex-on-high-cardinality-tag"
+	LintDeleteWithoutWhere   = "delete-without-where"
+	LintFunctionOnString     = "function-on-string-field"
+)
+
+// highCardinalityTagValues is the number of values from which a tag counts as high
+// cardinality, SHOW TAG VALUES is capped at the same limit
+const highCardinalityTagValues = tagValuesLimit
+
+// aggregateFunctions are the aggregates and selectors, a SELECT without any is a raw query
+var aggregateFunctions = map[string]bool{
+	"count": true, "distinct": true, "integral": true, "mean": true, "median": true, "mode": true,
+	"spread": true, "stddev": true, "sum": true, "bottom": true, "first": true, "last": true,
+	"max": true, "min": true, "percentile": true, "sample": true, "top": true,
+}
+
+// stringFunctions accept string fields, every other function requires numbers
+var stringFunctions = map[string]bool{
+	"count": true, "distinct": true, "first": true, "last": true, "mode": true, "sample": true,
+	"top": true, "bottom": true,
+}
+
+// LintQuery checks the query text for syntax errors and risky statements without executing
+// it. Rules needing the schema, such as functions applied to string fields, only run when
+// the request names a connected connection.
+func (app *App) LintQuery(req *LintRequest) ([]*LintWarning, error) {
+	var schema *lintSchema
+	if req.ConnectName != "" {
+		httpClient, err := app.getDialer(req.ConnectName)
+		if err != nil {
+			app.logger.Error("get opengemini client failed", "reason", err, "name", req.ConnectName)
+			return nil, err
+		}
+		schema = &lintSchema{app: app, httpClient: httpClient, connectName: req.ConnectName, database: req.Database}
+	}
+	return lintInfluxQL(ParseInfluxQL(req.Text), schema), nil
+}
+
+// lintInfluxQL applies the lint rules to parsed statements, schema may be nil
+func lintInfluxQL(parsed *ParseResult, schema *lintSchema) []*LintWarning {
+	var warnings = make([]*LintWarning, 0)
+	for _, err := range parsed.Errors {
+		warnings = append(warnings, &LintWarning{Rule: LintSyntax, Severity: SeverityError, Message: err.Message, Start: err.Start, End: err.End})
+	}
+	for _, stmt := range parsed.Statements {
+		if stmt.Error != nil {
+			continue
+		}
+		warnings = append(warnings, lintStatement(stmt, schema)...)
+	}
+	return warnings
+}
+
+func lintStatement(stmt *Statement, schema *lintSchema) []*LintWarning {
+	var warnings []*LintWarning
+	warn := func(rule, message string, start, end Position) {
+		warnings = append(warnings, &LintWarning{Rule: rule, Severity: SeverityWarning, Message: message, Start: start, End: end})
+	}
+
+	if stmt.Kind == "DELETE" || stmt.Kind == "DROP SERIES" {
+		if stmt.Condition == nil {
+			warn(LintDeleteWithoutWhere, fmt.Sprintf("%s without WHERE removes every series of the measurement", stmt.Kind), stmt.Start, stmt.End)
+		}
+		warnings = append(warnings, lintRegexConditions(stmt.Condition, stmt.Sources, schema)...)
+		return warnings
+	}
+
+	if stmt.Select != nil && stmt.Kind == "SELECT" && stmt.Select.Into == nil && !stmt.Select.HasLimit && isRawSelect(stmt.Select) {
+		warn(LintRawNoLimit, "raw query without LIMIT may return every point of the time range", stmt.Select.Start, stmt.Select.SourcesEnd)
+	}
+
+	// Time conditions of the outer query apply to its subqueries as well
+	outerTimeBound := stmt.Select != nil && HasTimeCondition(stmt.Select.Condition)
+	for _, selectStmt := range stmt.Selects {
+		var measurements []*MeasurementRef
+		for _, source := range selectStmt.Sources {
+			if ref, ok := source.(*MeasurementRef); ok {
+				measurements = append(measurements, ref)
+			}
+		}
+
+		if len(measurements) > 0 && !outerTimeBound && !HasTimeCondition(selectStmt.Condition) {
+			for _, field := range selectStmt.Fields {
+				if wildcard, ok := field.Expr.(*Wildcard); ok {
+					warn(LintSelectStarNoTime, "SELECT * without a time range reads every field of every series", wildcard.Start, wildcard.EndPos)
+				}
+			}
+		}
+
+		for _, dimension := range selectStmt.Dimensions {
+			if wildcard, ok := dimension.(*Wildcard); ok {
+				warn(LintGroupByStar, "GROUP BY * returns one series per tag combination", wildcard.Start, wildcard.EndPos)
+			}
+		}
+
+		warnings = append(warnings, lintRegexConditions(selectStmt.Condition, measurements, schema)...)
+		warnings = append(warnings, lintStringFunctions(selectStmt, measurements, schema)...)
+	}
+	return warnings
+}
+
+// isRawSelect reports whether the SELECT returns points rather than aggregated values
+func isRawSelect(stmt *SelectStatement) bool {
+	for _, dimension := range stmt.Dimensions {
+		if call, ok := dimension.(*Call); ok && call.Name == "time" {
+			return false
+		}
+	}
+	var aggregated bool
+	for _, field := range stmt.Fields {
+		WalkExpr(field.Expr, func(expr Expr) {
+			if call, ok := expr.(*Call); ok && aggregateFunctions[call.Name] {
+				aggregated = true
+			}
+		})
+	}
+	return !aggregated
+}
+
+// lintRegexConditions flags regular expressions matched against high cardinality tags
+func lintRegexConditions(condition Expr, measurements []*MeasurementRef, schema *lintSchema) []*LintWarning {
+	if schema == nil || condition == nil {
+		return nil
+	}
+	var warnings []*LintWarning
+	WalkExpr(condition, func(expr Expr) {
+		binary, ok := expr.(*BinaryExpr)
+		if !ok || binary.Op != "=~" && binary.Op != "!~" {
+			return
+		}
+		ref, ok := binary.LHS.(*VarRef)
+		if !ok {
+			return
+		}
+		for _, measurement := range measurements {
+			if !schema.isTag(measurement, ref.Name) {
+				continue
+			}
+			if count := schema.tagValueCount(measurement, ref.Name); count >= highCardinalityTagValues {
+				start, end := binary.Span()
+				warnings = append(warnings, &LintWarning{
+					Rule:     LintRegexHighCardinality,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("regular expression on tag %s with at least %d values scans every series of %s", ref.Name, count, measurement.Name),
+					Start:    start,
+					End:      end,
+				})
+				return
+			}
+		}
+	})
+	return warnings
+}
+
+// lintStringFunctions flags numeric functions applied to string fields
+func lintStringFunctions(stmt *SelectStatement, measurements []*MeasurementRef, schema *lintSchema) []*LintWarning {
+	if schema == nil {
+		return nil
+	}
+	var warnings []*LintWarning
+	for _, field := range stmt.Fields {
+		WalkExpr(field.Expr, func(expr Expr) {
+			call, ok := expr.(*Call)
+			if !ok || stringFunctions[call.Name] || len(call.Args) == 0 {
+				return
+			}
+			ref, ok := call.Args[0].(*VarRef)
+			if !ok || ref.Type == "tag" {
+				return
+			}
+			for _, measurement := range measurements {
+				if schema.fieldType(measurement, ref.Name) == "string" {
+					warnings = append(warnings, &LintWarning{
+						Rule:     LintFunctionOnString,
+						Severity: SeverityWarning,
+						Message:  fmt.Sprintf("%s() does not support string field %s of %s", call.Name, ref.Name, measurement.Name),
+						Start:    call.Start,
+						End:      call.EndPos,
+					})
+					return
+				}
+			}
+		})
+	}
+	return warnings
+}
+
+// lintSchema looks up the schema through the cache of a connection, lookup failures are
+// logged and treated as unknown schema
+type lintSchema struct {
+	app         *App
+	httpClient  HttpClient
+	connectName string
+	database    string
+}
+
+func (s *lintSchema) databaseOf(measurement *MeasurementRef) string {
+	if measurement.Database != "" {
+		return measurement.Database
+	}
+	return s.database
+}
+
+func (s *lintSchema) usable(measurement *MeasurementRef) bool {
+	return !measurement.IsRegex && measurement.Name != "" && s.databaseOf(measurement) != ""
+}
+
+func (s *lintSchema) isTag(measurement *MeasurementRef, name string) bool {
+	if !s.usable(measurement) {
+		return false
+	}
+	tagKeys, err := s.app.schemas.TagKeys(s.app.ctx, s.httpClient, s.connectName, s.databaseOf(measurement), measurement.Name)
+	if err != nil {
+		s.app.logger.Warn("load tag keys failed", "reason", err, "measurement", measurement.Name)
+		return false
+	}
+	return slices.Contains(tagKeys, name)
+}
+
+func (s *lintSchema) tagValueCount(measurement *MeasurementRef, key string) int {
+	values, err := s.app.schemas.TagValues(s.app.ctx, s.httpClient, s.connectName, s.databaseOf(measurement), measurement.Name, key)
+	if err != nil {
+		s.app.logger.Warn("load tag values failed", "reason", err, "measurement", measurement.Name, "key", key)
+		return 0
+	}
+	return len(values)
+}
+
+func (s *lintSchema) fieldType(measurement *MeasurementRef, name string) string {
+	if !s.usable(measurement) {
+		return ""
+	}
+	fieldKeys, err := s.app.schemas.FieldKeys(s.app.ctx, s.httpClient, s.connectName, s.databaseOf(measurement), measurement.Name)
+	if err != nil {
+		s.app.logger.Warn("load field keys failed", "reason", err, "measurement", measurement.Name)
+		return ""
+	}
+	for _, fieldKey := range fieldKeys {
+		if fieldKey.Name == name {
+			return fieldKey.Type
+		}
+	}
+	return ""
+}
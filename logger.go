@@ -15,60 +15,479 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	slogmulti "github.com/samber/slog-multi"
 )
 
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+
+	defaultLogMaxSize    = 10 // Megabytes
+	defaultLogMaxAge     = 7  // Days
+	defaultLogMaxBackups = 5
+	logRingSize          = 2000
+	defaultLogTailLimit  = 200
+)
+
 type Logger struct {
-	logger        *slog.Logger
-	defaultLogger *slog.Logger
-	fileHandler   *os.File
+	mu          sync.Mutex
+	logger      *slog.Logger
+	level       slog.LevelVar
+	format      string
+	fileHandler *rotatingFile
+	ring        *logRing
 }
 
 func NewLogger() *Logger {
-	var log = new(Logger)
-	log.defaultLogger = slog.Default()
+	var log = &Logger{format: LogFormatText, ring: newLogRing(logRingSize)}
+	log.level.Set(slog.LevelInfo)
 
-	logName := filepath.Join(workDirectory, "app.log")
-	handle, err := os.OpenFile(logName, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	handle, err := openRotatingFile(filepath.Join(workDirectory, "app.log"))
 	if err != nil {
-		log.defaultLogger.Error("open log file failed, use default logger", "reason", err)
-		return log
+		slog.Default().Error("open log file failed, log to stdout only", "reason", err)
+	} else {
+		log.fileHandler = handle
 	}
-	log.fileHandler = handle
-	defaultOpts := &slog.HandlerOptions{AddSource: true, Level: slog.LevelDebug}
-	log.logger = slog.New(slogmulti.Fanout(
-		slog.NewTextHandler(os.Stdout, defaultOpts),
-		slog.NewTextHandler(handle, defaultOpts),
-	))
+	log.build()
+	return log
+}
 
+// build puts the handlers of the current format in place
+func (log *Logger) build() {
+	opts := &slog.HandlerOptions{AddSource: true, Level: &log.level}
+	handlers := []slog.Handler{
+		slog.NewTextHandler(os.Stdout, opts),
+		&ringHandler{ring: log.ring, level: &log.level},
+	}
+	if log.fileHandler != nil {
+		if log.format == LogFormatJSON {
+			handlers = append(handlers, slog.NewJSONHandler(log.fileHandler, opts))
+		} else {
+			handlers = append(handlers, slog.NewTextHandler(log.fileHandler, opts))
+		}
+	}
+	log.logger = slog.New(slogmulti.Fanout(handlers...))
 	slog.SetDefault(log.logger)
+}
 
-	return log
+// checkLogSetting validates the log settings, nil stands for the defaults
+func checkLogSetting(setting *LogSetting) error {
+	if setting == nil {
+		return nil
+	}
+	if _, err := parseLogLevel(setting.Level); err != nil {
+		return err
+	}
+	switch setting.Format {
+	case "", LogFormatText, LogFormatJSON:
+	default:
+		return fmt.Errorf("unsupported log format: %s", setting.Format)
+	}
+	if setting.MaxSize < 0 || setting.MaxAge < 0 || setting.MaxBackups < 0 {
+		return fmt.Errorf("log rotation limits cannot be negative")
+	}
+	return nil
+}
+
+func parseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unsupported log level: %s", level)
+}
+
+// Configure applies the log settings at runtime, debug forces the debug level
+func (log *Logger) Configure(setting *LogSetting, debug bool) error {
+	if err := checkLogSetting(setting); err != nil {
+		return err
+	}
+	if setting == nil {
+		setting = &LogSetting{}
+	}
+	level, _ := parseLogLevel(setting.Level)
+	if debug {
+		level = slog.LevelDebug
+	}
+	log.level.Set(level)
+
+	format := setting.Format
+	if format == "" {
+		format = LogFormatText
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	if log.fileHandler != nil {
+		log.fileHandler.setLimits(setting.MaxSize, setting.MaxAge, setting.MaxBackups)
+	}
+	if format != log.format {
+		log.format = format
+		log.build()
+	}
+	return nil
 }
 
 func (log *Logger) Close() {
+	log.mu.Lock()
+	defer log.mu.Unlock()
 	if log.fileHandler == nil {
 		return
 	}
 	_ = log.fileHandler.Close()
 }
 
+// Reopen moves the log file to app.log of the current work directory, keeping its rotation
+// limits. The Logger stays the same, callers holding it keep logging to the new file.
+func (log *Logger) Reopen() error {
+	handle, err := openRotatingFile(filepath.Join(workDirectory, "app.log"))
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	if old := log.fileHandler; old != nil {
+		if handle != nil {
+			old.mu.Lock()
+			handle.maxSize, handle.maxAge, handle.maxBackups = old.maxSize, old.maxAge, old.maxBackups
+			old.mu.Unlock()
+		}
+		_ = old.Close()
+	}
+	log.fileHandler = handle
+	log.build()
+	return err
+}
+
 func (log *Logger) Debug(msg string, args ...interface{}) {
-	slog.Debug(msg, args...)
+	log.log(slog.LevelDebug, msg, args...)
 }
 
 func (log *Logger) Info(msg string, args ...interface{}) {
-	slog.Info(msg, args...)
+	log.log(slog.LevelInfo, msg, args...)
 }
 
 func (log *Logger) Warn(msg string, args ...interface{}) {
-	slog.Warn(msg, args...)
+	log.log(slog.LevelWarn, msg, args...)
 }
 
 func (log *Logger) Error(msg string, args ...interface{}) {
-	slog.Error(msg, args...)
+	log.log(slog.LevelError, msg, args...)
+}
+
+// log records the caller of the Logger method as source, not this file
+func (log *Logger) log(level slog.Level, msg string, args ...interface{}) {
+	log.mu.Lock()
+	logger := log.logger
+	log.mu.Unlock()
+	ctx := context.Background()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	record.Add(args...)
+	_ = logger.Handler().Handle(ctx, record)
+}
+
+// Tail returns the recent entries accepted by filter, oldest first
+func (log *Logger) Tail(filter *LogFilter) *LogPage {
+	return log.ring.tail(filter)
+}
+
+// TailLogs returns the recent log entries after the AfterSeq of the filter, following the
+// log is polling with the LastSeq of the previous page
+func (app *App) TailLogs(filter *LogFilter) (*LogPage, error) {
+	if filter == nil {
+		filter = &LogFilter{}
+	}
+	if _, err := parseLogLevel(filter.Level); err != nil {
+		return nil, err
+	}
+	return app.logger.Tail(filter), nil
+}
+
+// rotatingFile is app.log, rotated to app-<time>.log when it exceeds its size or age
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	file       *os.File
+	size       int64
+	opened     time.Time
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+}
+
+func openRotatingFile(path string) (*rotatingFile, error) {
+	f := &rotatingFile{path: path}
+	f.setLimits(0, 0, 0)
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// setLimits sets the rotation limits, zero values stand for the defaults
+func (f *rotatingFile) setLimits(maxSize, maxAge, maxBackups int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.maxSize = int64(cmp.Or(maxSize, defaultLogMaxSize)) << 20
+	f.maxAge = time.Duration(cmp.Or(maxAge, defaultLogMaxAge)) * 24 * time.Hour
+	f.maxBackups = cmp.Or(maxBackups, defaultLogMaxBackups)
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	// The age of a file continued from a previous launch counts from its first entry
+	f.opened = time.Now()
+	if f.size > 0 {
+		f.opened = firstEntryTime(file, info.ModTime())
+	}
+	return nil
+}
+
+// firstEntryTime reads the time of the first entry of the log file, text and JSON entries
+// both start with it. fallback is returned when the file does not start with an entry.
+func firstEntryTime(file *os.File, fallback time.Time) time.Time {
+	var head = make([]byte, 128)
+	n, _ := file.ReadAt(head, 0)
+	line := string(head[:n])
+	for _, prefix := range []string{`time=`, `{"time":"`} {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			value, _, _ := strings.Cut(rest, " ")
+			value, _, _ = strings.Cut(value, `"`)
+			if stamp, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				return stamp
+			}
+		}
+	}
+	return fallback
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.size > 0 && (f.size+int64(len(p)) > f.maxSize || time.Since(f.opened) > f.maxAge) {
+		if err := f.rotate(); err != nil {
+			// Keep logging to the current file rather than losing entries
+			_, _ = fmt.Fprintf(os.Stderr, "rotate log file failed: %v\n", err)
+		}
+		if f.file == nil {
+			return 0, os.ErrClosed
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	rotated := strings.TrimSuffix(f.path, ".log") + "-" + time.Now().Format(backupTimeLayout) + ".log"
+	renameErr := os.Rename(f.path, rotated)
+	if err := f.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+	f.prune()
+	return nil
+}
+
+// prune removes the rotated files beyond maxBackups or older than maxAge
+func (f *rotatingFile) prune() {
+	pattern := strings.TrimSuffix(f.path, ".log") + "-*.log"
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return
+	}
+	// Names end with the rotation time, newest first
+	slices.Sort(files)
+	slices.Reverse(files)
+	for i, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if i >= f.maxBackups || time.Since(info.ModTime()) > f.maxAge {
+			_ = os.Remove(file)
+		}
+	}
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+var _ io.WriteCloser = (*rotatingFile)(nil)
+
+// logRing keeps the recent entries in memory for the log panel
+type logRing struct {
+	mu      sync.Mutex
+	entries []*LogEntry
+	next    int
+	seq     int64
+}
+
+func newLogRing(size int) *logRing {
+	return &logRing{entries: make([]*LogEntry, 0, size)}
+}
+
+func (r *logRing) add(entry *LogEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	entry.Seq = r.seq
+	if len(r.entries) < cap(r.entries) {
+		r.entries = append(r.entries, entry)
+		return
+	}
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+}
+
+func (r *logRing) tail(filter *LogFilter) *LogPage {
+	var (
+		minLevel, _ = parseLogLevel(filter.Level)
+		words       = strings.Fields(strings.ToLower(filter.Text))
+		limit       = filter.Limit
+	)
+	if limit <= 0 {
+		limit = defaultLogTailLimit
+	}
+	r.mu.Lock()
+	ordered := append(slices.Clone(r.entries[r.next:]), r.entries[:r.next]...)
+	page := &LogPage{Entries: make([]*LogEntry, 0), LastSeq: r.seq}
+	r.mu.Unlock()
+
+	for _, entry := range ordered {
+		if entry.Seq <= filter.AfterSeq || entry.level < minLevel || !entry.matches(words) {
+			continue
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+	// The newest entries when more match than the limit
+	if len(page.Entries) > limit {
+		page.Entries = page.Entries[len(page.Entries)-limit:]
+	}
+	return page
+}
+
+func (entry *LogEntry) matches(words []string) bool {
+	if len(words) == 0 {
+		return true
+	}
+	var text strings.Builder
+	text.WriteString(entry.Message)
+	for key, value := range entry.Attrs {
+		text.WriteString("\n" + key + "=" + value)
+	}
+	lower := strings.ToLower(text.String())
+	for _, word := range words {
+		if !strings.Contains(lower, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringHandler is the slog handler feeding the log ring
+type ringHandler struct {
+	ring   *logRing
+	level  slog.Leveler
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h *ringHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *ringHandler) Handle(_ context.Context, record slog.Record) error {
+	entry := &LogEntry{
+		Time:    record.Time.UnixMilli(),
+		Level:   record.Level.String(),
+		Message: record.Message,
+		Attrs:   make(map[string]string),
+		level:   record.Level,
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		entry.Source = fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+	}
+	for _, attr := range h.attrs {
+		entry.Attrs[attr.Key] = attr.Value.Resolve().String()
+	}
+	prefix := strings.Join(h.groups, ".")
+	record.Attrs(func(attr slog.Attr) bool {
+		key := attr.Key
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		entry.Attrs[key] = attr.Value.Resolve().String()
+		return true
+	})
+	h.ring.add(entry)
+	return nil
+}
+
+func (h *ringHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	// Attributes are stored with the key of their group
+	clone := *h
+	clone.attrs = slices.Clone(h.attrs)
+	prefix := strings.Join(h.groups, ".")
+	for _, attr := range attrs {
+		if prefix != "" {
+			attr.Key = prefix + "." + attr.Key
+		}
+		clone.attrs = append(clone.attrs, attr)
+	}
+	return &clone
+}
+
+func (h *ringHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.groups = append(slices.Clone(h.groups), name)
+	return &clone
 }
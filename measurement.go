@@ -0,0 +1,224 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openGemini/opengemini-client-go/opengemini"
+)
+
+const (
+	EngineTypeTSStore     = "tsstore"
+	EngineTypeColumnStore = "columnstore"
+
+	ShardTypeHash  = "hash"
+	ShardTypeRange = "range"
+)
+
+// measurementKeyStatements are the openGemini statements describing a measurement's
+// storage engine, in the order their results are read by showMeasurementInfo
+var measurementKeyStatements = []string{"ENGINETYPE", "PRIMARYKEY", "SORTKEY", "SHARDKEY"}
+
+// measurementInfoBatch limits the number of measurements described per request
+const measurementInfoBatch = 50
+
+var columnTypeKeywords = map[string]string{
+	"tag":     "TAG",
+	"int64":   "INT64 FIELD",
+	"float64": "FLOAT64 FIELD",
+	"string":  "STRING FIELD",
+	"bool":    "BOOL FIELD",
+}
+
+func (app *App) CreateMeasurement(req *CreateMeasurementRequest) error {
+	command, err := buildCreateMeasurement(req)
+	if err != nil {
+		return err
+	}
+	httpClient, err := app.getDialer(req.ConnectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", req.ConnectName)
+		return err
+	}
+	if err := app.guardBuiltCommand(req.ConnectName, req.Database, command); err != nil {
+		return err
+	}
+	if _, err := querySingle(app.ctx, httpClient, req.Database, command); err != nil {
+		app.logger.Error("create measurement failed", "reason", err, "command", command)
+		return fmt.Errorf("create measurement failed: %w", err)
+	}
+	app.schemas.Invalidate(req.ConnectName)
+	app.logger.Info("create measurement", "name", req.Name, "db", req.Database, "engine", req.EngineType)
+	return nil
+}
+
+func buildCreateMeasurement(req *CreateMeasurementRequest) (string, error) {
+	if req.Database == "" {
+		return "", errors.New("database required")
+	}
+	if req.Name == "" {
+		return "", errors.New("measurement name required")
+	}
+	engineType := strings.ToLower(req.EngineType)
+	if engineType == "" {
+		engineType = EngineTypeTSStore
+	}
+	if engineType != EngineTypeTSStore && engineType != EngineTypeColumnStore {
+		return "", fmt.Errorf("unsupported engine type: %s", req.EngineType)
+	}
+	shardType := strings.ToLower(req.ShardType)
+	if shardType != "" && shardType != ShardTypeHash && shardType != ShardTypeRange {
+		return "", fmt.Errorf("unsupported shard type: %s", req.ShardType)
+	}
+	if engineType == EngineTypeColumnStore && len(req.Columns) == 0 {
+		return "", errors.New("column store measurement requires column definitions")
+	}
+	if len(req.SortKeys) > 0 && (len(req.PrimaryKeys) > len(req.SortKeys) ||
+		!slices.Equal(req.PrimaryKeys, req.SortKeys[:len(req.PrimaryKeys)])) {
+		return "", errors.New("primary keys must be a prefix of sort keys")
+	}
+
+	var command strings.Builder
+	command.WriteString("CREATE MEASUREMENT ")
+	if req.RetentionPolicy != "" {
+		command.WriteString(quoteIdentifier(req.Database) + "." + quoteIdentifier(req.RetentionPolicy) + ".")
+	}
+	command.WriteString(quoteIdentifier(req.Name))
+
+	if len(req.Columns) > 0 {
+		columns := make([]string, 0, len(req.Columns))
+		for _, column := range req.Columns {
+			keyword, ok := columnTypeKeywords[strings.ToLower(column.Type)]
+			if !ok {
+				return "", fmt.Errorf("unsupported column type %q for column %s", column.Type, column.Name)
+			}
+			if column.Name == "" {
+				return "", errors.New("column name required")
+			}
+			columns = append(columns, quoteIdentifier(column.Name)+" "+keyword)
+		}
+		command.WriteString(" (" + strings.Join(columns, ", ") + ")")
+	}
+
+	command.WriteString(" WITH ENGINETYPE = " + engineType)
+	if len(req.ShardKeys) > 0 {
+		command.WriteString(" SHARDKEY " + joinIdentifiers(req.ShardKeys))
+		if shardType != "" {
+			command.WriteString(" TYPE " + shardType)
+		}
+	}
+	if len(req.PrimaryKeys) > 0 {
+		command.WriteString(" PRIMARYKEY " + joinIdentifiers(req.PrimaryKeys))
+	}
+	if len(req.SortKeys) > 0 {
+		command.WriteString(" SORTKEY " + joinIdentifiers(req.SortKeys))
+	}
+	return command.String(), nil
+}
+
+// GetMeasurementInfo describes the storage engine and keys of the given measurements, bypassing
+// the cached descriptions of DatabaseMetadata. The statements are openGemini specific, other
+// servers return an error.
+func (app *App) GetMeasurementInfo(connectName, database string, measurements []string) ([]*MeasurementInfo, error) {
+	httpClient, err := app.getDialer(connectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", connectName)
+		return nil, err
+	}
+	infos, err := showMeasurementInfo(app.ctx, httpClient, database, measurements)
+	if err != nil {
+		app.logger.Warn("get measurement info failed", "reason", err, "db", database)
+		return infos, fmt.Errorf("get measurement info failed: %w", err)
+	}
+	return infos, nil
+}
+
+// showMeasurementInfo describes the storage engine and keys of every measurement. The
+// statements are openGemini specific, so the first failing batch stops the lookup and the
+// measurements gathered so far are returned together with the error.
+func showMeasurementInfo(ctx context.Context, httpClient HttpClient, database string, measurements []string) ([]*MeasurementInfo, error) {
+	var infos = make([]*MeasurementInfo, 0, len(measurements))
+	for start := 0; start < len(measurements); start += measurementInfoBatch {
+		batch := measurements[start:min(start+measurementInfoBatch, len(measurements))]
+		statements := make([]string, 0, len(batch)*len(measurementKeyStatements))
+		for _, measurement := range batch {
+			for _, key := range measurementKeyStatements {
+				statements = append(statements, "SHOW "+key+" FROM "+quoteIdentifier(measurement))
+			}
+		}
+		response, err := httpClient.Query(ctx, &opengemini.Query{
+			Database: database,
+			Command:  strings.Join(statements, "; "),
+		})
+		if err != nil {
+			return infos, err
+		}
+		if response.Error != "" {
+			return infos, errors.New(response.Error)
+		}
+
+		for i, measurement := range batch {
+			var (
+				info   = &MeasurementInfo{Name: measurement}
+				offset = i * len(measurementKeyStatements)
+			)
+			if offset+len(measurementKeyStatements) > len(response.Results) {
+				infos = append(infos, info)
+				continue
+			}
+			if values := seriesResultStrings(response.Results[offset]); len(values) > 0 {
+				info.EngineType = strings.ToLower(values[0])
+			}
+			info.PrimaryKeys = seriesResultStrings(response.Results[offset+1])
+			info.SortKeys = seriesResultStrings(response.Results[offset+2])
+			info.ShardKeys = seriesResultStrings(response.Results[offset+3])
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+// seriesResultStrings collects the first-column string values of a statement result,
+// splitting comma separated lists. Statement errors yield no values.
+func seriesResultStrings(result *opengemini.SeriesResult) []string {
+	if result == nil || result.Error != "" {
+		return nil
+	}
+	var values []string
+	for _, series := range result.Series {
+		for _, row := range series.Values {
+			if len(row) == 0 {
+				continue
+			}
+			if value, ok := row[0].(string); ok {
+				values = append(values, splitList(value)...)
+			}
+		}
+	}
+	return values
+}
+
+func joinIdentifiers(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, quoteIdentifier(name))
+	}
+	return strings.Join(quoted, ",")
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const metaSchemaVersion = "schema_version"
+
+// migration upgrades the store from version-1 to version. Migrations run in order at
+// startup, each in its own transaction together with the update of the schema version.
+type migration struct {
+	version int
+	name    string
+	apply   func(tx *bolt.Tx) error
+}
+
+// migrations are append only, a released migration must never change
+var migrations = []migration{
+	{version: 1, name: "time-ordered history keys", apply: migrateHistoryKeys},
+}
+
+// schemaVersion is the version of the stores written by this build
+func schemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// migrateDatabase brings the store to the current schema version. A store created by an
+// older build is copied to the backups folder before it is migrated; a store of a newer
+// build is refused rather than risking writes it does not understand.
+func migrateDatabase(db *bolt.DB, logger *Logger) error {
+	var (
+		version int
+		fresh   bool
+	)
+	err := db.View(func(tx *bolt.Tx) error {
+		// Stores without meta bucket predate versioning, they are version 0
+		fresh = tx.Bucket([]byte(BucketConnections)) == nil
+		var err error
+		version, err = readSchemaVersion(tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	target := schemaVersion()
+	if version > target {
+		return fmt.Errorf("config store schema version %d is newer than the supported version %d", version, target)
+	}
+
+	if !fresh && version < target {
+		path, err := backupDatabase(db, BackupReasonMigration)
+		if err != nil {
+			return fmt.Errorf("backup config store failed: %w", err)
+		}
+		logger.Info("backup config store before migration", "path", path, "version", version)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if err := createBuckets(tx); err != nil {
+			return err
+		}
+		if fresh {
+			// New stores are created at the current version
+			version = target
+			return writeSchemaVersion(tx, target)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+		err := db.Update(func(tx *bolt.Tx) error {
+			if err := m.apply(tx); err != nil {
+				return err
+			}
+			return writeSchemaVersion(tx, m.version)
+		})
+		if err != nil {
+			logger.Error("migrate config store failed", "reason", err, "version", m.version, "migration", m.name)
+			return fmt.Errorf("migrate config store to version %d failed: %w", m.version, err)
+		}
+		logger.Info("migrate config store", "version", m.version, "migration", m.name)
+	}
+	return nil
+}
+
+func readSchemaVersion(tx *bolt.Tx) (int, error) {
+	bucket := tx.Bucket([]byte(BucketMeta))
+	if bucket == nil {
+		return 0, nil
+	}
+	value := bucket.Get([]byte(metaSchemaVersion))
+	if value == nil {
+		return 0, nil
+	}
+	if len(value) != 8 {
+		return 0, errors.New("invalid config store schema version")
+	}
+	return int(binary.BigEndian.Uint64(value)), nil
+}
+
+func writeSchemaVersion(tx *bolt.Tx, version int) error {
+	var value = make([]byte, 8)
+	binary.BigEndian.PutUint64(value, uint64(version))
+	return tx.Bucket([]byte(BucketMeta)).Put([]byte(metaSchemaVersion), value)
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// setupMigrationTest points the work directory to a temporary directory and returns a
+// logger writing into it
+func setupMigrationTest(t *testing.T) *Logger {
+	t.Helper()
+	workDirectory = t.TempDir()
+	logger := NewLogger()
+	t.Cleanup(logger.Close)
+	return logger
+}
+
+// writeFixture creates config.db with the buckets and records written by fill
+func writeFixture(t *testing.T, fill func(tx *bolt.Tx) error) {
+	t.Helper()
+	db, err := bolt.Open(databasePath(), 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.Update(fill); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readVersion(t *testing.T, db *bolt.DB) int {
+	t.Helper()
+	var version int
+	err := db.View(func(tx *bolt.Tx) error {
+		var err error
+		version, err = readSchemaVersion(tx)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return version
+}
+
+// TestMigrateLegacyStore migrates a store of the builds before versioning, whose histories
+// are keyed by their millisecond timestamp
+func TestMigrateLegacyStore(t *testing.T) {
+	logger := setupMigrationTest(t)
+
+	// Millisecond keys of different lengths sort wrongly as strings, two histories of the
+	// same millisecond must both survive
+	var timestamps = []int64{999999999999, 1700000000000, 1700000000001, 1700000000001}
+	writeFixture(t, func(tx *bolt.Tx) error {
+		for _, name := range []string{BucketConnections, BucketSettings, BucketHistories} {
+			if _, err := tx.CreateBucket([]byte(name)); err != nil {
+				return err
+			}
+		}
+		if err := tx.Bucket([]byte(BucketSettings)).Put([]byte("system"), defaultAppSetting.Marshal()); err != nil {
+			return err
+		}
+		for i, timestamp := range timestamps {
+			key := strconv.FormatInt(timestamp+int64(i/3), 10)
+			data, err := json.Marshal(&History{ID: key, Query: "SELECT " + strconv.Itoa(i), Timestamp: timestamp})
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket([]byte(BucketHistories)).Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	db, err := ConnectDatabase(logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if version := readVersion(t, db); version != schemaVersion() {
+		t.Fatalf("schema version = %d, want %d", version, schemaVersion())
+	}
+	var histories []*History
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(BucketHistories)).ForEach(func(k, v []byte) error {
+			var history = &History{}
+			if err := json.Unmarshal(v, history); err != nil {
+				return err
+			}
+			if len(k) != historyIDLength || history.ID != string(k) {
+				t.Errorf("history key %q, id %q: want a %d digit key equal to the id", k, history.ID, historyIDLength)
+			}
+			histories = append(histories, history)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != len(timestamps) {
+		t.Fatalf("%d histories after migration, want %d", len(histories), len(timestamps))
+	}
+	// Keys are in bucket order, which must be the order of the timestamps
+	if !slices.IsSortedFunc(histories, func(a, b *History) int { return int(a.Timestamp - b.Timestamp) }) {
+		t.Errorf("histories are not in time order after migration")
+	}
+	if want := formatHistoryID(time.UnixMilli(timestamps[0]).UnixNano()); histories[0].ID != want {
+		t.Errorf("first history id = %s, want %s", histories[0].ID, want)
+	}
+
+	backups, err := (&App{}).ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || backups[0].Reason != BackupReasonMigration {
+		t.Fatalf("backups = %v, want a single migration backup", backups)
+	}
+	// The backup is the store as it was before the migration
+	backup, err := bolt.Open(filepath.Join(backupDirectoryPath(), backups[0].Name), 0600, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+	if version := readVersion(t, backup); version != 0 {
+		t.Errorf("backup schema version = %d, want 0", version)
+	}
+	err = backup.View(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(BucketHistories)).Get([]byte("1700000000000")) == nil {
+			t.Errorf("backup lacks the legacy history keys")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestMigrateFreshStore creates a store at the current version without a backup
+func TestMigrateFreshStore(t *testing.T) {
+	logger := setupMigrationTest(t)
+
+	db, err := ConnectDatabase(logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if version := readVersion(t, db); version != schemaVersion() {
+		t.Fatalf("schema version = %d, want %d", version, schemaVersion())
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		for _, name := range []string{BucketMeta, BucketConnections, BucketSettings, BucketHistories, BucketSavedQueries} {
+			if tx.Bucket([]byte(name)) == nil {
+				t.Errorf("bucket %s missing", name)
+			}
+		}
+		if tx.Bucket([]byte(BucketSettings)).Get([]byte("system")) == nil {
+			t.Errorf("default settings missing")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backups, err := (&App{}).ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 0 {
+		t.Errorf("backups = %v, want none for a fresh store", backups)
+	}
+}
+
+// TestMigrateNewerStore refuses a store written by a newer build and leaves it untouched
+func TestMigrateNewerStore(t *testing.T) {
+	logger := setupMigrationTest(t)
+
+	newer := schemaVersion() + 1
+	writeFixture(t, func(tx *bolt.Tx) error {
+		for _, name := range []string{BucketMeta, BucketConnections, BucketSettings, BucketHistories} {
+			if _, err := tx.CreateBucket([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return writeSchemaVersion(tx, newer)
+	})
+
+	if db, err := ConnectDatabase(logger); err == nil {
+		db.Close()
+		t.Fatal("store of a newer schema version was opened")
+	}
+
+	db, err := bolt.Open(databasePath(), 0600, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if version := readVersion(t, db); version != newer {
+		t.Errorf("schema version = %d, want %d", version, newer)
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(BucketSavedQueries)) != nil {
+			t.Errorf("buckets were created in the newer store")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const defaultQueryStatsLimit = 10
+
+// GetQueryStats aggregates the execution records of the history: the slowest queries,
+// percentiles per normalized query shape and the failure rate per connection
+func (app *App) GetQueryStats(req *QueryStatsRequest) (*QueryStatsReport, error) {
+	histories, err := app.loadHistories(func(history *History) bool {
+		return (req.ConnectName == "" || history.ConnectName == req.ConnectName) &&
+			(req.Since == 0 || history.Timestamp >= req.Since) &&
+			(req.Until == 0 || history.Timestamp <= req.Until)
+	})
+	if err != nil {
+		app.logger.Error("load histories failed", "reason", err)
+		return nil, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultQueryStatsLimit
+	}
+	return aggregateQueryStats(histories, limit), nil
+}
+
+func aggregateQueryStats(histories []*History, limit int) *QueryStatsReport {
+	var (
+		report      = &QueryStatsReport{Total: len(histories)}
+		shapes      = make(map[string]*QueryShapeStats)
+		shapeTimes  = make(map[string][]float64)
+		shapeRows   = make(map[string]int64)
+		connections = make(map[string]*ConnectionStats)
+		connTimes   = make(map[string][]float64)
+	)
+	for _, history := range histories {
+		shape := normalizeQueryShape(history.Query)
+		stats, ok := shapes[shape]
+		if !ok {
+			stats = &QueryShapeStats{Shape: shape}
+			shapes[shape] = stats
+		}
+		stats.Count++
+		stats.LastSeen = max(stats.LastSeen, history.Timestamp)
+		stats.Max = max(stats.Max, history.ExecutionTime)
+		shapeTimes[shape] = append(shapeTimes[shape], history.ExecutionTime)
+		if history.Stats != nil {
+			shapeRows[shape] += history.Stats.Rows
+		}
+
+		connection, ok := connections[history.ConnectName]
+		if !ok {
+			connection = &ConnectionStats{ConnectName: history.ConnectName}
+			connections[history.ConnectName] = connection
+		}
+		connection.Count++
+		connTimes[history.ConnectName] = append(connTimes[history.ConnectName], history.ExecutionTime)
+
+		if !history.Success {
+			report.Failures++
+			stats.Failures++
+			connection.Failures++
+		}
+	}
+
+	report.Shapes = make([]*QueryShapeStats, 0, len(shapes))
+	for shape, stats := range shapes {
+		stats.P50 = percentile(shapeTimes[shape], 50)
+		stats.P95 = percentile(shapeTimes[shape], 95)
+		stats.AverageRows = float64(shapeRows[shape]) / float64(stats.Count)
+		report.Shapes = append(report.Shapes, stats)
+	}
+	sort.Slice(report.Shapes, func(i, j int) bool {
+		if report.Shapes[i].P95 != report.Shapes[j].P95 {
+			return report.Shapes[i].P95 > report.Shapes[j].P95
+		}
+		return report.Shapes[i].Shape < report.Shapes[j].Shape
+	})
+	report.Shapes = report.Shapes[:min(limit, len(report.Shapes))]
+
+	report.Connections = make([]*ConnectionStats, 0, len(connections))
+	for name, connection := range connections {
+		connection.FailureRate = float64(connection.Failures) / float64(connection.Count)
+		connection.P50 = percentile(connTimes[name], 50)
+		connection.P95 = percentile(connTimes[name], 95)
+		report.Connections = append(report.Connections, connection)
+	}
+	sort.Slice(report.Connections, func(i, j int) bool {
+		return report.Connections[i].ConnectName < report.Connections[j].ConnectName
+	})
+
+	report.Slowest = slices.Clone(histories)
+	sort.SliceStable(report.Slowest, func(i, j int) bool {
+		return report.Slowest[i].ExecutionTime > report.Slowest[j].ExecutionTime
+	})
+	report.Slowest = report.Slowest[:min(limit, len(report.Slowest))]
+	return report
+}
+
+// percentile returns the nearest-rank percentile p of values
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// normalizeQueryShape reduces a query to its shape: literals become ?, keywords are upper
+// case, comments are dropped and whitespace collapsed, so that runs of the same query with
+// different values are aggregated together
+func normalizeQueryShape(text string) string {
+	var (
+		scanner = NewScanner(text)
+		builder strings.Builder
+		prev    Token
+		start   = true
+	)
+	write := func(token Token, text string) {
+		attached := token.Type == TokenLParen && prev.Type == TokenIdent || token.Type == TokenRParen ||
+			token.Type == TokenComma || token.Type == TokenDot || prev.Type == TokenLParen || prev.Type == TokenDot
+		if builder.Len() > 0 && !attached {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(text)
+		prev = token
+	}
+	for {
+		token := scanner.Scan()
+		switch token.Type {
+		case TokenEOF:
+			return builder.String()
+		case TokenWS, TokenComment:
+			continue
+		case TokenString, TokenInteger, TokenNumber, TokenDuration, TokenRegex, TokenBoundParam:
+			write(token, "?")
+		case TokenKeyword:
+			write(token, token.Value)
+		default:
+			write(token, token.Text)
+		}
+		if start && token.IsKeyword("INSERT") {
+			// Line protocol is data, the shape only keeps the measurement
+			fields := strings.Fields(scanner.ScanLineProtocol().Value)
+			if len(fields) > 2 && strings.EqualFold(fields[0], "INTO") {
+				fields = fields[2:]
+			}
+			if len(fields) > 0 {
+				measurement, _, _ := strings.Cut(fields[0], ",")
+				write(Token{Type: TokenIdent}, measurement+" ?")
+			}
+		}
+		// A statement starts after ';' and after the line protocol of an INSERT
+		start = token.Type == TokenSemicolon || token.IsKeyword("INSERT")
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/options"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	bolt "go.etcd.io/bbolt"
+)
+
+// instanceID identifies the instances sharing the data directory, only one of them runs
+func instanceID() string {
+	sum := sha256.Sum256([]byte(workDirectory))
+	return "io.opengemini.studio." + hex.EncodeToString(sum[:8])
+}
+
+// onSecondInstanceLaunch brings the window of the running instance to the front
+func (app *App) onSecondInstanceLaunch(data options.SecondInstanceData) {
+	app.logger.Info("second instance launched", "args", data.Args)
+	runtime.WindowUnminimise(app.ctx)
+	runtime.Show(app.ctx)
+}
+
+// openDatabase opens the config store, salvaging it when it is corrupted
+func (app *App) openDatabase() error {
+	db, err := ConnectDatabase(app.logger)
+	if errors.Is(err, DatabaseCorruptedError) {
+		app.logger.Error("config store is corrupted, salvaging", "reason", err)
+		report, salvageErr := app.salvageDatabase()
+		if salvageErr != nil {
+			app.logger.Error("salvage config store failed", "reason", salvageErr)
+			return fmt.Errorf("salvage config store failed: %w", salvageErr)
+		}
+		app.recovery = report
+		db, err = ConnectDatabase(app.logger)
+	}
+	if err != nil {
+		app.logger.Error("open database failed", "reason", err)
+		return err
+	}
+	app.db = db
+	return nil
+}
+
+// GetRecoveryReport describes the salvage of a corrupted config store at startup, nil when
+// the store opened normally
+func (app *App) GetRecoveryReport() *RecoveryReport {
+	return app.recovery
+}
+
+type salvagedItem struct {
+	key      []byte
+	value    []byte
+	children []salvagedItem // Items of a nested bucket, value is nil
+}
+
+// salvageDatabase copies the readable records of the damaged config.db into a fresh store
+// and keeps the damaged file beside it. When nothing can be read, the newest valid backup
+// is used instead.
+func (app *App) salvageDatabase() (*RecoveryReport, error) {
+	var (
+		path    = databasePath()
+		salvage = path + ".salvage"
+		report  = &RecoveryReport{
+			CorruptedFile: fmt.Sprintf("%s.corrupted-%s", path, time.Now().Format(backupTimeLayout)),
+			Buckets:       make([]*SalvagedBucket, 0),
+		}
+	)
+	_ = os.Remove(salvage)
+	if err := salvageInto(path, salvage, report); err != nil {
+		app.logger.Warn("read corrupted config store failed", "reason", err)
+		report.Error = err.Error()
+		report.Buckets = report.Buckets[:0]
+		_ = os.Remove(salvage)
+
+		backups, _ := app.ListBackups()
+		for _, backup := range backups {
+			if validateBackup(filepath.Join(backupDirectoryPath(), backup.Name)) != nil {
+				continue
+			}
+			if err := copyPath(filepath.Join(backupDirectoryPath(), backup.Name), salvage); err != nil {
+				return nil, err
+			}
+			report.Backup = backup.Name
+			break
+		}
+	}
+
+	if err := os.Rename(path, report.CorruptedFile); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(salvage); err == nil {
+		if err := os.Rename(salvage, path); err != nil {
+			return nil, err
+		}
+	}
+	app.logger.Warn("config store salvaged", "corrupted_file", report.CorruptedFile, "backup", report.Backup)
+	for _, bucket := range report.Buckets {
+		app.logger.Warn("salvaged bucket", "name", bucket.Name, "recovered", bucket.Recovered,
+			"complete", bucket.Complete, "reason", bucket.Error)
+	}
+	return report, nil
+}
+
+// salvageInto opens src read only and copies every record it can read into dst
+func salvageInto(src, dst string, report *RecoveryReport) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	source, err := bolt.Open(src, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	target, err := bolt.Open(dst, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	return source.View(func(tx *bolt.Tx) error {
+		var names [][]byte
+		err := tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			names = append(names, slices.Clone(name))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			bucket := &SalvagedBucket{Name: string(name)}
+			report.Buckets = append(report.Buckets, bucket)
+			items, readErr := readBucket(tx.Bucket(name))
+			bucket.Complete = readErr == nil
+			if readErr != nil {
+				bucket.Error = readErr.Error()
+			}
+			err := target.Update(func(tx *bolt.Tx) error {
+				b, err := tx.CreateBucketIfNotExists(name)
+				if err != nil {
+					return err
+				}
+				bucket.Recovered, err = writeBucket(b, items)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// readBucket returns the records of the bucket up to the first unreadable page
+func readBucket(bucket *bolt.Bucket) (items []salvagedItem, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	if bucket == nil {
+		return nil, errors.New("bucket is unreadable")
+	}
+	cursor := bucket.Cursor()
+	for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+		item := salvagedItem{key: slices.Clone(k), value: slices.Clone(v)}
+		if v == nil {
+			item.children, err = readBucket(bucket.Bucket(k))
+			items = append(items, item)
+			if err != nil {
+				return items, err
+			}
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// writeBucket stores the items in bucket and returns the number of records written
+func writeBucket(bucket *bolt.Bucket, items []salvagedItem) (int, error) {
+	var count int
+	for _, item := range items {
+		if item.value == nil {
+			child, err := bucket.CreateBucketIfNotExists(item.key)
+			if err != nil {
+				return count, err
+			}
+			n, err := writeBucket(child, item.children)
+			count += n
+			if err != nil {
+				return count, err
+			}
+			continue
+		}
+		if err := bucket.Put(item.key, item.value); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
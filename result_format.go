@@ -0,0 +1,125 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	TimeFormatRFC3339 = "rfc3339"
+	TimeFormatHuman   = "human"
+	TimeFormatEpoch   = "epoch"
+
+	humanTimeLayout = "2006-01-02 15:04:05.000"
+)
+
+// resultFormat returns the format of the request, or the default of the settings
+func (app *App) resultFormat(data *ExecuteRequest) *ResultFormat {
+	if data.Format != nil {
+		return data.Format
+	}
+	setting, err := app.GetSetting()
+	if err != nil {
+		return nil
+	}
+	return setting.ResultFormat
+}
+
+// timeColumnFormatter renders the time column of a result. The server returns times as
+// RFC3339 strings, which keep nanoseconds that JSON numbers would lose.
+type timeColumnFormatter struct {
+	format   string
+	unit     time.Duration
+	location *time.Location
+}
+
+// newTimeColumnFormatter checks the format options, location is the tz() clause of the
+// statement and takes precedence over the timezone of the options
+func newTimeColumnFormatter(format *ResultFormat, location string) (*timeColumnFormatter, error) {
+	formatter := &timeColumnFormatter{format: format.TimeFormat, unit: time.Nanosecond, location: time.UTC}
+	switch format.TimeFormat {
+	case "":
+		formatter.format = TimeFormatRFC3339
+	case TimeFormatRFC3339, TimeFormatHuman:
+	case TimeFormatEpoch:
+		switch format.EpochUnit {
+		case "", "ns":
+		case "us", "u":
+			formatter.unit = time.Microsecond
+		case "ms":
+			formatter.unit = time.Millisecond
+		case "s":
+			formatter.unit = time.Second
+		default:
+			return nil, fmt.Errorf("unsupported epoch unit: %s", format.EpochUnit)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported time format: %s", format.TimeFormat)
+	}
+
+	timezone := format.Timezone
+	if location != "" {
+		timezone = location
+	}
+	if timezone != "" {
+		loaded, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone: %s", timezone)
+		}
+		formatter.location = loaded
+	}
+	return formatter, nil
+}
+
+// apply rewrites the time column of values in place
+func (f *timeColumnFormatter) apply(columns []string, values [][]any) {
+	index := slices.Index(columns, "time")
+	if index == -1 {
+		return
+	}
+	for _, row := range values {
+		if index >= len(row) {
+			continue
+		}
+		text, ok := row[index].(string)
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, text)
+		if err != nil {
+			continue
+		}
+		row[index] = f.render(t)
+	}
+}
+
+func (f *timeColumnFormatter) render(t time.Time) any {
+	switch f.format {
+	case TimeFormatEpoch:
+		epoch := t.UnixNano() / int64(f.unit)
+		if f.unit < time.Millisecond {
+			// Beyond the 53 bits JavaScript numbers hold exactly
+			return strconv.FormatInt(epoch, 10)
+		}
+		return epoch
+	case TimeFormatHuman:
+		return t.In(f.location).Format(humanTimeLayout)
+	}
+	return t.In(f.location).Format(time.RFC3339Nano)
+}
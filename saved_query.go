@@ -0,0 +1,301 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	SavedQueryNotExistError = errors.New("saved query does not exist")
+)
+
+// ListSavedQueries returns every saved query ordered by folder and name
+func (app *App) ListSavedQueries() ([]*SavedQuery, error) {
+	return app.SearchSavedQueries(&SavedQueryFilter{})
+}
+
+// ListSavedQueryFolders returns the folders holding saved queries, with their parents
+func (app *App) ListSavedQueryFolders() ([]string, error) {
+	queries, err := app.ListSavedQueries()
+	if err != nil {
+		return nil, err
+	}
+	var folders = make([]string, 0)
+	for _, query := range queries {
+		for folder := query.Folder; folder != ""; folder = parentFolder(folder) {
+			if !slices.Contains(folders, folder) {
+				folders = append(folders, folder)
+			}
+		}
+	}
+	sort.Strings(folders)
+	return folders, nil
+}
+
+// SearchSavedQueries returns the saved queries matching the filter, ordered by folder and name
+func (app *App) SearchSavedQueries(filter *SavedQueryFilter) ([]*SavedQuery, error) {
+	var (
+		queries = make([]*SavedQuery, 0)
+		words   = strings.Fields(strings.ToLower(filter.Text))
+		folder  = normalizeFolder(filter.Folder)
+	)
+	err := app.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketSavedQueries))
+		return bucket.ForEach(func(k, v []byte) error {
+			var query = &SavedQuery{}
+			if err := json.Unmarshal(v, query); err != nil {
+				return err
+			}
+			if folder != "" && query.Folder != folder && !strings.HasPrefix(query.Folder, folder+"/") {
+				return nil
+			}
+			for _, tag := range filter.Tags {
+				if !slices.Contains(query.Tags, tag) {
+					return nil
+				}
+			}
+			text := strings.ToLower(query.Name + "\n" + query.Description + "\n" + query.Query)
+			for _, word := range words {
+				if !strings.Contains(text, word) {
+					return nil
+				}
+			}
+			queries = append(queries, query)
+			return nil
+		})
+	})
+	if err != nil {
+		app.logger.Error("list saved queries failed", "reason", err)
+		return nil, err
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Folder != queries[j].Folder {
+			return queries[i].Folder < queries[j].Folder
+		}
+		return strings.ToLower(queries[i].Name) < strings.ToLower(queries[j].Name)
+	})
+	return queries, nil
+}
+
+func (app *App) GetSavedQuery(id string) (*SavedQuery, error) {
+	var query = &SavedQuery{}
+	err := app.view(func(tx *bolt.Tx) error {
+		content := tx.Bucket([]byte(BucketSavedQueries)).Get([]byte(id))
+		if content == nil {
+			return SavedQueryNotExistError
+		}
+		return json.Unmarshal(content, query)
+	})
+	if err != nil {
+		app.logger.Error("get saved query failed", "reason", err, "id", id)
+		return nil, err
+	}
+	return query, nil
+}
+
+// CreateSavedQuery stores a new saved query and returns it with its ID
+func (app *App) CreateSavedQuery(query *SavedQuery) (*SavedQuery, error) {
+	if err := normalizeSavedQuery(query); err != nil {
+		return nil, err
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, fmt.Errorf("generate saved query id failed: %w", err)
+	}
+	query.ID = id
+	query.CreatedAt = time.Now().UnixMilli()
+	query.UpdatedAt = query.CreatedAt
+
+	err = app.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketSavedQueries))
+		if err := checkSavedQueryName(bucket, query); err != nil {
+			return err
+		}
+		return putSavedQuery(bucket, query)
+	})
+	if err != nil {
+		app.logger.Error("create saved query failed", "reason", err, "name", query.Name)
+		return nil, err
+	}
+	app.logger.Info("create saved query", "name", query.Name, "folder", query.Folder)
+	return query, nil
+}
+
+// UpdateSavedQuery replaces the saved query with the same ID
+func (app *App) UpdateSavedQuery(query *SavedQuery) error {
+	if err := normalizeSavedQuery(query); err != nil {
+		return err
+	}
+	err := app.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketSavedQueries))
+		existing, err := getSavedQuery(bucket, query.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkSavedQueryName(bucket, query); err != nil {
+			return err
+		}
+		query.CreatedAt = existing.CreatedAt
+		query.UpdatedAt = time.Now().UnixMilli()
+		return putSavedQuery(bucket, query)
+	})
+	if err != nil {
+		app.logger.Error("update saved query failed", "reason", err, "id", query.ID)
+		return err
+	}
+	return nil
+}
+
+func (app *App) DeleteSavedQuery(id string) error {
+	err := app.update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(BucketSavedQueries)).Delete([]byte(id))
+	})
+	if err != nil {
+		app.logger.Error("delete saved query failed", "reason", err, "id", id)
+	}
+	return err
+}
+
+// MoveSavedQuery moves the saved query into folder, empty for the root
+func (app *App) MoveSavedQuery(id, folder string) error {
+	err := app.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketSavedQueries))
+		query, err := getSavedQuery(bucket, id)
+		if err != nil {
+			return err
+		}
+		query.Folder = normalizeFolder(folder)
+		if err := checkSavedQueryName(bucket, query); err != nil {
+			return err
+		}
+		query.UpdatedAt = time.Now().UnixMilli()
+		return putSavedQuery(bucket, query)
+	})
+	if err != nil {
+		app.logger.Error("move saved query failed", "reason", err, "id", id, "folder", folder)
+	}
+	return err
+}
+
+// DuplicateSavedQuery copies the saved query into the same folder under a free name such
+// as "name (copy)"
+func (app *App) DuplicateSavedQuery(id string) (*SavedQuery, error) {
+	newID, err := randomHex(8)
+	if err != nil {
+		return nil, fmt.Errorf("generate saved query id failed: %w", err)
+	}
+	var duplicate *SavedQuery
+	err = app.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketSavedQueries))
+		query, err := getSavedQuery(bucket, id)
+		if err != nil {
+			return err
+		}
+		duplicate = query
+		duplicate.ID = newID
+		duplicate.CreatedAt = time.Now().UnixMilli()
+		duplicate.UpdatedAt = duplicate.CreatedAt
+		duplicate.Name = freeName(query.Name, "copy", func(name string) bool {
+			return checkSavedQueryName(bucket, &SavedQuery{ID: newID, Name: name, Folder: duplicate.Folder}) != nil
+		})
+		return putSavedQuery(bucket, duplicate)
+	})
+	if err != nil {
+		app.logger.Error("duplicate saved query failed", "reason", err, "id", id)
+		return nil, err
+	}
+	return duplicate, nil
+}
+
+func getSavedQuery(bucket *bolt.Bucket, id string) (*SavedQuery, error) {
+	content := bucket.Get([]byte(id))
+	if content == nil {
+		return nil, SavedQueryNotExistError
+	}
+	var query = &SavedQuery{}
+	return query, json.Unmarshal(content, query)
+}
+
+func putSavedQuery(bucket *bolt.Bucket, query *SavedQuery) error {
+	content, err := json.Marshal(query)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(query.ID), content)
+}
+
+// checkSavedQueryName rejects a name already used by another saved query of the folder
+func checkSavedQueryName(bucket *bolt.Bucket, query *SavedQuery) error {
+	return bucket.ForEach(func(k, v []byte) error {
+		if string(k) == query.ID {
+			return nil
+		}
+		var other = &SavedQuery{}
+		if err := json.Unmarshal(v, other); err != nil {
+			return err
+		}
+		if other.Folder == query.Folder && strings.EqualFold(other.Name, query.Name) {
+			return fmt.Errorf("saved query %s already exists in folder %q", query.Name, query.Folder)
+		}
+		return nil
+	})
+}
+
+func normalizeSavedQuery(query *SavedQuery) error {
+	query.Name = strings.TrimSpace(query.Name)
+	if query.Name == "" {
+		return errors.New("saved query name required")
+	}
+	if strings.TrimSpace(query.Query) == "" {
+		return errors.New("query required")
+	}
+	query.Folder = normalizeFolder(query.Folder)
+	var tags = make([]string, 0, len(query.Tags))
+	for _, tag := range query.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	query.Tags = tags
+	return nil
+}
+
+// normalizeFolder trims the separators of a folder path, "/a//b/" becomes "a/b"
+func normalizeFolder(folder string) string {
+	var parts []string
+	for _, part := range strings.Split(folder, "/") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func parentFolder(folder string) string {
+	index := strings.LastIndexByte(folder, '/')
+	if index == -1 {
+		return ""
+	}
+	return folder[:index]
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	schemaCacheTTL = 5 * time.Minute
+	tagValuesLimit = 1000
+)
+
+type schemaEntry struct {
+	value    any
+	loadedAt time.Time
+}
+
+// SchemaCache keeps the schema of each connection for completion and linting. Entries are
+// loaded lazily on first use, expire after schemaCacheTTL and are dropped for a
+// connection when it executes DDL or writes.
+type SchemaCache struct {
+	mu      sync.Mutex
+	entries map[string]map[string]*schemaEntry // connection name -> key -> entry
+}
+
+func NewSchemaCache() *SchemaCache {
+	return &SchemaCache{entries: make(map[string]map[string]*schemaEntry)}
+}
+
+// Invalidate drops everything cached for the connection
+func (c *SchemaCache) Invalidate(connectName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, connectName)
+}
+
+func (c *SchemaCache) Databases(ctx context.Context, httpClient HttpClient, connectName string) ([]string, error) {
+	return schemaLookup(c, connectName, "databases", func() ([]string, error) {
+		return httpClient.Databases(ctx)
+	})
+}
+
+func (c *SchemaCache) RetentionPolicies(ctx context.Context, httpClient HttpClient, connectName, database string) ([]*RetentionPolicy, error) {
+	return schemaLookup(c, connectName, "rp\x00"+database, func() ([]*RetentionPolicy, error) {
+		return httpClient.RetentionPolicies(ctx, database)
+	})
+}
+
+func (c *SchemaCache) Measurements(ctx context.Context, httpClient HttpClient, connectName, database string) ([]string, error) {
+	return schemaLookup(c, connectName, "measurements\x00"+database, func() ([]string, error) {
+		return httpClient.Measurements(ctx, database)
+	})
+}
+
+func (c *SchemaCache) TagKeys(ctx context.Context, httpClient HttpClient, connectName, database, measurement string) ([]string, error) {
+	return schemaLookup(c, connectName, "tagkeys\x00"+database+"\x00"+measurement, func() ([]string, error) {
+		return httpClient.TagKeys(ctx, database, measurement)
+	})
+}
+
+func (c *SchemaCache) FieldKeys(ctx context.Context, httpClient HttpClient, connectName, database, measurement string) ([]*FieldKey, error) {
+	return schemaLookup(c, connectName, "fieldkeys\x00"+database+"\x00"+measurement, func() ([]*FieldKey, error) {
+		return httpClient.FieldKeys(ctx, database, measurement)
+	})
+}
+
+func (c *SchemaCache) TagValues(ctx context.Context, httpClient HttpClient, connectName, database, measurement, key string) ([]string, error) {
+	return schemaLookup(c, connectName, "tagvalues\x00"+database+"\x00"+measurement+"\x00"+key, func() ([]string, error) {
+		return httpClient.TagValues(ctx, database, measurement, key, tagValuesLimit)
+	})
+}
+
+// MeasurementInfo describes the storage engine and keys of the measurements of the database.
+// The statements are openGemini specific, a failing lookup is cached with the measurements
+// described so far so that other servers are not asked again until the entry expires.
+func (c *SchemaCache) MeasurementInfo(ctx context.Context, httpClient HttpClient, connectName, database string, measurements []string, logger *Logger) ([]*MeasurementInfo, error) {
+	return schemaLookup(c, connectName, "measurementinfo\x00"+database, func() ([]*MeasurementInfo, error) {
+		infos, err := showMeasurementInfo(ctx, httpClient, database, measurements)
+		if err != nil {
+			logger.Warn("get measurement info failed", "reason", err, "db", database)
+		}
+		return infos, nil
+	})
+}
+
+func schemaLookup[T any](c *SchemaCache, connectName, key string, load func() (T, error)) (T, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[connectName][key]
+	c.mu.Unlock()
+	if ok && time.Since(entry.loadedAt) < schemaCacheTTL {
+		return entry.value.(T), nil
+	}
+
+	value, err := load()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[connectName] == nil {
+		c.entries[connectName] = make(map[string]*schemaEntry)
+	}
+	c.entries[connectName][key] = &schemaEntry{value: value, loadedAt: time.Now()}
+	return value, nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func (app *App) ListStreams(connectName, database string) ([]*StreamTask, error) {
+	httpClient, err := app.getDialer(connectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", connectName)
+		return nil, err
+	}
+	streams, err := showStreams(app.ctx, httpClient, database)
+	if err != nil {
+		app.logger.Error("show streams failed", "reason", err, "db", database)
+		return nil, err
+	}
+	return streams, nil
+}
+
+func (app *App) CreateStream(req *CreateStreamRequest) error {
+	if req.Name == "" {
+		return errors.New("stream name required")
+	}
+	stmt, err := parseSelectStatement(req.Select)
+	if err != nil {
+		return err
+	}
+	if groupByTime(stmt.Select) == nil {
+		return errors.New("stream requires a GROUP BY time() clause")
+	}
+	var target string
+	if req.Target != "" {
+		if target, err = quoteMeasurementPath(req.Target); err != nil {
+			return fmt.Errorf("invalid stream target: %w", err)
+		}
+	}
+	if req.Delay != "" {
+		if err := checkDurations("stream delay", req.Delay); err != nil {
+			return err
+		}
+	}
+	httpClient, err := app.getDialer(req.ConnectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", req.ConnectName)
+		return err
+	}
+
+	var command strings.Builder
+	command.WriteString("CREATE STREAM " + quoteIdentifier(req.Name))
+	if target != "" {
+		command.WriteString(" INTO " + target)
+	}
+	command.WriteString(" ON " + stmt.Text)
+	if req.Delay != "" {
+		command.WriteString(" DELAY " + req.Delay)
+	}
+
+	if err := app.guardBuiltCommand(req.ConnectName, req.Database, command.String()); err != nil {
+		return err
+	}
+	if _, err := querySingle(app.ctx, httpClient, req.Database, command.String()); err != nil {
+		app.logger.Error("create stream failed", "reason", err, "name", req.Name, "db", req.Database)
+		return fmt.Errorf("create stream failed: %w", err)
+	}
+	app.logger.Info("create stream", "name", req.Name, "db", req.Database)
+	return nil
+}
+
+// DropStream drops the stream. On production connections it returns a confirmation first,
+// the stream is dropped when called again with the confirmation token.
+func (app *App) DropStream(connectName, database, name, confirmToken string) (*ExecuteConfirmation, error) {
+	command := "DROP STREAM " + quoteIdentifier(name)
+	confirmation, err := app.guardCommand(connectName, database, confirmToken, command)
+	if err != nil || confirmation != nil {
+		return confirmation, err
+	}
+	httpClient, err := app.getDialer(connectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", connectName)
+		return nil, err
+	}
+	if _, err := querySingle(app.ctx, httpClient, database, command); err != nil {
+		app.logger.Error("drop stream failed", "reason", err, "name", name, "db", database)
+		return nil, fmt.Errorf("drop stream failed: %w", err)
+	}
+	app.logger.Info("drop stream", "name", name, "db", database)
+	return nil, nil
+}
+
+// quoteMeasurementPath reads [db.][rp.]measurement, the parts quoted or not, and returns it
+// with every part quoted
+func quoteMeasurementPath(path string) (string, error) {
+	var (
+		parts   []string
+		scanner = NewScanner(path)
+		dot     = true // A name is expected
+	)
+	for {
+		token := scanner.Scan()
+		switch {
+		case token.Type == TokenWS:
+			continue
+		case token.Type == TokenEOF:
+			if dot || len(parts) > 3 {
+				return "", fmt.Errorf("expected [database.][retention_policy.]measurement: %s", path)
+			}
+			return strings.Join(parts, "."), nil
+		case token.Type == TokenDot && !dot:
+			dot = true
+		case dot && token.Type == TokenQuotedIdent:
+			parts = append(parts, quoteIdentifier(token.Value))
+			dot = false
+		case dot && (token.Type == TokenIdent || token.Type == TokenKeyword):
+			parts = append(parts, quoteIdentifier(token.Text))
+			dot = false
+		default:
+			return "", fmt.Errorf("expected [database.][retention_policy.]measurement: %s", path)
+		}
+	}
+}
+
+func showStreams(ctx context.Context, httpClient HttpClient, database string) ([]*StreamTask, error) {
+	result, err := querySingle(ctx, httpClient, database, "SHOW STREAMS ON "+quoteIdentifier(database))
+	if err != nil {
+		return nil, fmt.Errorf("show streams failed: %w", err)
+	}
+
+	var streams []*StreamTask
+	for _, series := range result.Series {
+		columns := normalizedColumnIndex(series.Columns)
+		for _, row := range series.Values {
+			stream := parseStreamQuery(rowString(row, columns, "query"))
+			stream.Name = rowString(row, columns, "name")
+			stream.Database = database
+			if delay := rowString(row, columns, "delay"); delay != "" {
+				stream.Delay = delay
+			}
+			streams = append(streams, stream)
+		}
+	}
+	return streams, nil
+}
+
+// parseStreamQuery extracts the parts of a
+// CREATE STREAM name [INTO target] ON SELECT ... [DELAY d] statement
+func parseStreamQuery(query string) *StreamTask {
+	var stream = &StreamTask{Query: query}
+	parsed := ParseInfluxQL(query)
+	if len(parsed.Statements) != 1 {
+		return stream
+	}
+	var (
+		tokens          = parsed.Statements[0].Tokens
+		into, on, delay = -1, -1, -1
+	)
+	for i, token := range tokens {
+		switch {
+		case token.IsKeyword("INTO") && on == -1 && into == -1:
+			into = i
+		case token.IsKeyword("ON") && on == -1:
+			on = i
+		case token.IsKeyword("DELAY"):
+			delay = i
+		}
+	}
+	if on == -1 || on+1 >= len(tokens) {
+		return stream
+	}
+	if into != -1 && into+1 < on {
+		stream.Target = query[tokens[into+1].Pos.Offset:tokens[on-1].End.Offset]
+	}
+	last := len(tokens) - 1
+	if delay > on+1 {
+		if delay < last {
+			stream.Delay = query[tokens[delay+1].Pos.Offset:tokens[last].End.Offset]
+		}
+		last = delay - 1
+	}
+	stream.Select = query[tokens[on+1].Pos.Offset:tokens[last].End.Offset]
+	target, source, interval, _ := parseSelectParts(stream.Select)
+	if stream.Target == "" {
+		stream.Target = target
+	}
+	stream.Source, stream.Interval = source, interval
+	return stream
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	SubscriptionModeAll = "ALL"
+	SubscriptionModeAny = "ANY"
+)
+
+// ListSubscriptions returns the subscriptions of all databases, or only of database
+// when it is not empty
+func (app *App) ListSubscriptions(connectName, database string) ([]*Subscription, error) {
+	httpClient, err := app.getDialer(connectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", connectName)
+		return nil, err
+	}
+	result, err := querySingle(app.ctx, httpClient, "", "SHOW SUBSCRIPTIONS")
+	if err != nil {
+		app.logger.Error("show subscriptions failed", "reason", err, "name", connectName)
+		return nil, fmt.Errorf("show subscriptions failed: %w", err)
+	}
+
+	var subscriptions []*Subscription
+	for _, series := range result.Series {
+		if database != "" && series.Name != database {
+			continue
+		}
+		columns := normalizedColumnIndex(series.Columns)
+		for _, row := range series.Values {
+			subscription := &Subscription{
+				Database:        series.Name,
+				RetentionPolicy: rowString(row, columns, "retentionpolicy"),
+				Name:            rowString(row, columns, "name"),
+				Mode:            strings.ToUpper(rowString(row, columns, "mode")),
+			}
+			if i, ok := columns["destinations"]; ok && i < len(row) {
+				subscription.Destinations = parseSubscriptionDestinations(row[i])
+			}
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (app *App) CreateSubscription(req *CreateSubscriptionRequest) error {
+	if req.Database == "" || req.RetentionPolicy == "" {
+		return errors.New("database and retention policy required")
+	}
+	if req.Name == "" {
+		return errors.New("subscription name required")
+	}
+	mode := strings.ToUpper(req.Mode)
+	if mode == "" {
+		mode = SubscriptionModeAll
+	}
+	if mode != SubscriptionModeAll && mode != SubscriptionModeAny {
+		return fmt.Errorf("unsupported subscription mode: %s", req.Mode)
+	}
+	if len(req.Destinations) == 0 {
+		return errors.New("at least one subscription destination required")
+	}
+	destinations := make([]string, 0, len(req.Destinations))
+	for _, destination := range req.Destinations {
+		if _, err := newSubscriptionDestination(destination); err != nil {
+			return err
+		}
+		destinations = append(destinations, quoteString(destination))
+	}
+	httpClient, err := app.getDialer(req.ConnectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", req.ConnectName)
+		return err
+	}
+
+	command := fmt.Sprintf("CREATE SUBSCRIPTION %s ON %s.%s DESTINATIONS %s %s",
+		quoteIdentifier(req.Name), quoteIdentifier(req.Database), quoteIdentifier(req.RetentionPolicy),
+		mode, strings.Join(destinations, ", "))
+	if err := app.guardBuiltCommand(req.ConnectName, req.Database, command); err != nil {
+		return err
+	}
+	if _, err := querySingle(app.ctx, httpClient, req.Database, command); err != nil {
+		app.logger.Error("create subscription failed", "reason", err, "name", req.Name, "db", req.Database)
+		return fmt.Errorf("create subscription failed: %w", err)
+	}
+	app.logger.Info("create subscription", "name", req.Name, "db", req.Database, "rp", req.RetentionPolicy)
+	return nil
+}
+
+// DropSubscription drops the subscription. On production connections it returns a
+// confirmation first, the subscription is dropped when called again with the confirmation
+// token.
+func (app *App) DropSubscription(connectName, database, retentionPolicy, name, confirmToken string) (*ExecuteConfirmation, error) {
+	command := fmt.Sprintf("DROP SUBSCRIPTION %s ON %s.%s",
+		quoteIdentifier(name), quoteIdentifier(database), quoteIdentifier(retentionPolicy))
+	confirmation, err := app.guardCommand(connectName, database, confirmToken, command)
+	if err != nil || confirmation != nil {
+		return confirmation, err
+	}
+	httpClient, err := app.getDialer(connectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", connectName)
+		return nil, err
+	}
+	if _, err := querySingle(app.ctx, httpClient, database, command); err != nil {
+		app.logger.Error("drop subscription failed", "reason", err, "name", name, "db", database)
+		return nil, fmt.Errorf("drop subscription failed: %w", err)
+	}
+	app.logger.Info("drop subscription", "name", name, "db", database, "rp", retentionPolicy)
+	return nil, nil
+}
+
+// parseSubscriptionDestinations accepts the destinations column either as a JSON array or
+// as a comma separated string
+func parseSubscriptionDestinations(value any) []*SubscriptionDestination {
+	var raw []string
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	case string:
+		raw = splitList(strings.Trim(v, "[]"))
+	}
+
+	destinations := make([]*SubscriptionDestination, 0, len(raw))
+	for _, item := range raw {
+		destination, err := newSubscriptionDestination(item)
+		if err != nil {
+			destination = &SubscriptionDestination{URL: item}
+		}
+		destinations = append(destinations, destination)
+	}
+	return destinations
+}
+
+func newSubscriptionDestination(raw string) (*SubscriptionDestination, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription destination %q: %w", raw, err)
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" && scheme != "udp" {
+		return nil, fmt.Errorf("invalid subscription destination %q: scheme must be http, https or udp", raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid subscription destination %q: host required", raw)
+	}
+	return &SubscriptionDestination{URL: raw, Scheme: scheme, Host: u.Host}, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// textEdit replaces length bytes at offset by text, a zero length inserts it
+type textEdit struct {
+	offset int
+	length int
+	text   string
+}
+
+// applyTextEdits applies edits that do not overlap to text
+func applyTextEdits(text string, edits []textEdit) string {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].offset < edits[j].offset })
+	var (
+		builder strings.Builder
+		last    int
+	)
+	for _, edit := range edits {
+		builder.WriteString(text[last:edit.offset])
+		builder.WriteString(edit.text)
+		last = edit.offset + edit.length
+	}
+	builder.WriteString(text[last:])
+	return builder.String()
+}
+
+// InjectTimeRange adds the time range as a condition to the SELECT statements of text that
+// have no time condition, neither themselves nor in a subquery. Conditions already present
+// are kept and combined with AND.
+func InjectTimeRange(text string, parsed *ParseResult, timeRange *TimeRange, now time.Time) (string, error) {
+	from, to, err := resolveTimeRange(timeRange, now)
+	if err != nil {
+		return "", err
+	}
+	predicate := timeFilterPredicate(from, to)
+
+	var edits []textEdit
+	for _, stmt := range parsed.Statements {
+		if stmt.Select == nil || stmt.Error != nil || hasAnyTimeCondition(stmt) {
+			continue
+		}
+		if condition := stmt.Select.Condition; condition != nil {
+			start, end := condition.Span()
+			edits = append(edits,
+				textEdit{offset: start.Offset, text: "("},
+				textEdit{offset: end.Offset, text: ") AND " + predicate},
+			)
+			continue
+		}
+		edits = append(edits, textEdit{offset: stmt.Select.SourcesEnd.Offset, text: " WHERE " + predicate})
+	}
+	if len(edits) == 0 {
+		return text, nil
+	}
+	return applyTextEdits(text, edits), nil
+}
+
+// hasAnyTimeCondition reports whether any SELECT of the statement restricts time
+func hasAnyTimeCondition(stmt *Statement) bool {
+	for _, selectStmt := range stmt.Selects {
+		if HasTimeCondition(selectStmt.Condition) {
+			return true
+		}
+	}
+	return false
+}
+
+func timeFilterPredicate(from, to time.Time) string {
+	return fmt.Sprintf("time >= %s AND time <= %s", quoteString(from.Format(time.RFC3339Nano)), quoteString(to.Format(time.RFC3339Nano)))
+}
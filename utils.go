@@ -15,8 +15,12 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 var workDirectory = filepath.Join(GetHomeDir(), ".opengemini-studio")
@@ -32,3 +36,45 @@ func GetHomeDir() string {
 func CreateWorkDirectory() error {
 	return os.MkdirAll(workDirectory, 0750)
 }
+
+// quoteString renders s as a single-quoted InfluxQL string literal
+func quoteString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+// quoteIdentifier renders s as a double-quoted InfluxQL identifier
+func quoteIdentifier(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// formatIdentifier renders s as an InfluxQL identifier, quoted only when required
+func formatIdentifier(s string) string {
+	if !plainIdentRegexp.MatchString(s) || isKeyword(strings.ToUpper(s)) {
+		return quoteIdentifier(s)
+	}
+	return s
+}
+
+// randomHex returns size random bytes in hex
+func randomHex(size int) (string, error) {
+	var buf = make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// freeName returns the first of "name (suffix)", "name (suffix 2)", ... that is not taken
+func freeName(name, suffix string, taken func(string) bool) string {
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%s)", name, suffix)
+		if i > 1 {
+			candidate = fmt.Sprintf("%s (%s %d)", name, suffix, i)
+		}
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,368 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	VariableString     = "string"
+	VariableIdentifier = "identifier"
+	VariableNumber     = "number"
+	VariableDuration   = "duration"
+	VariableTime       = "time"
+)
+
+const defaultMaxDataPoints = 1000
+
+// Built-in variables derived from the time range of the request
+const (
+	variableTimeFilter = "__timeFilter"
+	variableInterval   = "__interval"
+	variableFrom       = "__from"
+	variableTo         = "__to"
+)
+
+var (
+	durationPartRegexp = regexp.MustCompile(`(\d+)(ns|us|u|µ|ms|s|m|h|d|w)`)
+	relativeTimeRegexp = regexp.MustCompile(`(?i)^now(?:\(\))?\s*(?:([+-])\s*(\S+))?$`)
+
+	// localTimeLayouts are accepted for times without offset, read in the time range timezone
+	localTimeLayouts = []string{"2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+	// intervalSteps are the values $__interval is rounded up to
+	intervalSteps = []time.Duration{
+		time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond, time.Second, 5 * time.Second,
+		10 * time.Second, 15 * time.Second, 30 * time.Second, time.Minute, 5 * time.Minute,
+		10 * time.Minute, 15 * time.Minute, 30 * time.Minute, time.Hour, 3 * time.Hour, 6 * time.Hour,
+		12 * time.Hour, 24 * time.Hour, 7 * 24 * time.Hour,
+	}
+)
+
+// ListQueryVariables returns the names of the user variables referenced by the query text,
+// in order of first use and without the built-in ones
+func (app *App) ListQueryVariables(text string) []string {
+	var names = make([]string, 0)
+	for _, token := range variableTokens(text) {
+		if !isBuiltinVariable(token.Value) && !slices.Contains(names, token.Value) {
+			names = append(names, token.Value)
+		}
+	}
+	return names
+}
+
+// ListVariableValues returns the values a tag variable may take
+func (app *App) ListVariableValues(req *VariableValuesRequest) ([]string, error) {
+	if req.Database == "" {
+		return nil, errors.New("database required")
+	}
+	if req.TagKey == "" {
+		return nil, errors.New("tag key required")
+	}
+	httpClient, err := app.getDialer(req.ConnectName)
+	if err != nil {
+		app.logger.Error("get opengemini client failed", "reason", err, "name", req.ConnectName)
+		return nil, err
+	}
+	values, err := app.schemas.TagValues(app.ctx, httpClient, req.ConnectName, req.Database, req.Measurement, req.TagKey)
+	if err != nil {
+		app.logger.Error("list variable values failed", "reason", err, "db", req.Database, "key", req.TagKey)
+		return nil, err
+	}
+	return values, nil
+}
+
+// ExpandQuery returns the statements of the request with variables expanded and the time
+// range applied, as they would be sent by ExecuteCommand
+func (app *App) ExpandQuery(data *ExecuteRequest) ([]string, error) {
+	parsed, err := prepareCommand(data, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var statements = make([]string, 0, len(parsed.Statements))
+	for _, stmt := range parsed.Statements {
+		statements = append(statements, stmt.Text)
+	}
+	return statements, nil
+}
+
+// ExpandVariables replaces the $name variables of text with their quoted values. Strings,
+// comments and line protocol are left untouched.
+func ExpandVariables(text string, variables []*QueryVariable, timeRange *TimeRange, now time.Time) (string, error) {
+	tokens := variableTokens(text)
+	if len(tokens) == 0 {
+		return text, nil
+	}
+
+	var (
+		builder strings.Builder
+		last    int
+	)
+	for _, token := range tokens {
+		value, err := variableValue(token.Value, variables, timeRange, now)
+		if err != nil {
+			return "", fmt.Errorf("expand variable $%s at line %d, column %d failed: %w", token.Value, token.Pos.Line, token.Pos.Column, err)
+		}
+		builder.WriteString(text[last:token.Pos.Offset])
+		builder.WriteString(value)
+		last = token.End.Offset
+	}
+	builder.WriteString(text[last:])
+	return builder.String(), nil
+}
+
+// variableTokens scans the bound parameter tokens of text, skipping INSERT line protocol
+func variableTokens(text string) []Token {
+	var (
+		scanner = NewScanner(text)
+		tokens  []Token
+		start   = true
+	)
+	for {
+		token := scanner.Scan()
+		switch token.Type {
+		case TokenEOF:
+			return tokens
+		case TokenWS, TokenComment:
+			continue
+		case TokenBoundParam:
+			tokens = append(tokens, token)
+		}
+		if start && token.IsKeyword("INSERT") {
+			scanner.ScanLineProtocol()
+		}
+		// A statement starts after ';' and after the line protocol of an INSERT
+		start = token.Type == TokenSemicolon || token.IsKeyword("INSERT")
+	}
+}
+
+func isBuiltinVariable(name string) bool {
+	switch name {
+	case variableTimeFilter, variableInterval, variableFrom, variableTo:
+		return true
+	}
+	return false
+}
+
+func variableValue(name string, variables []*QueryVariable, timeRange *TimeRange, now time.Time) (string, error) {
+	if isBuiltinVariable(name) {
+		return builtinVariableValue(name, timeRange, now)
+	}
+
+	index := slices.IndexFunc(variables, func(variable *QueryVariable) bool { return variable.Name == name })
+	if index == -1 {
+		return "", errors.New("undefined variable")
+	}
+	variable := variables[index]
+	value := variable.Value
+	if value == "" {
+		value = variable.Default
+	}
+	if value == "" {
+		return "", errors.New("value required")
+	}
+
+	switch variable.Type {
+	case VariableString, "":
+		return quoteString(value), nil
+	case VariableIdentifier:
+		return formatIdentifier(value), nil
+	case VariableNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("invalid number: %s", value)
+		}
+		return value, nil
+	case VariableDuration:
+		if _, err := parseInfluxDuration(value); err != nil {
+			return "", err
+		}
+		return value, nil
+	case VariableTime:
+		location, err := timeRangeLocation(timeRange)
+		if err != nil {
+			return "", err
+		}
+		t, err := parseTimeBound(value, now, location)
+		if err != nil {
+			return "", err
+		}
+		return quoteString(t.UTC().Format(time.RFC3339Nano)), nil
+	}
+	return "", fmt.Errorf("unsupported variable type: %s", variable.Type)
+}
+
+func builtinVariableValue(name string, timeRange *TimeRange, now time.Time) (string, error) {
+	if timeRange == nil || timeRange.From == "" {
+		return "", errors.New("time range required")
+	}
+	from, to, err := resolveTimeRange(timeRange, now)
+	if err != nil {
+		return "", err
+	}
+	switch name {
+	case variableTimeFilter:
+		return timeFilterPredicate(from, to), nil
+	case variableFrom:
+		return quoteString(from.Format(time.RFC3339Nano)), nil
+	case variableTo:
+		return quoteString(to.Format(time.RFC3339Nano)), nil
+	}
+
+	maxDataPoints := timeRange.MaxDataPoints
+	if maxDataPoints <= 0 {
+		maxDataPoints = defaultMaxDataPoints
+	}
+	interval := to.Sub(from) / time.Duration(maxDataPoints)
+	for _, step := range intervalSteps {
+		if step >= interval {
+			return formatInfluxDuration(step), nil
+		}
+	}
+	return formatInfluxDuration(intervalSteps[len(intervalSteps)-1]), nil
+}
+
+// resolveTimeRange returns the absolute UTC bounds of the range
+func resolveTimeRange(timeRange *TimeRange, now time.Time) (time.Time, time.Time, error) {
+	location, err := timeRangeLocation(timeRange)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	from, err := parseTimeBound(timeRange.From, now, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to := now
+	if timeRange.To != "" {
+		if to, err = parseTimeBound(timeRange.To, now, location); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("time range start must be before its end")
+	}
+	return from.UTC(), to.UTC(), nil
+}
+
+// timeRangeLocation loads the timezone of the range, UTC when unset
+func timeRangeLocation(timeRange *TimeRange) (*time.Location, error) {
+	if timeRange == nil || timeRange.Timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timeRange.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %s", timeRange.Timezone)
+	}
+	return location, nil
+}
+
+// parseTimeBound parses RFC3339 times, Unix milliseconds, times relative to now such as
+// now-1h or now()-6h, and times without offset which are read in location
+func parseTimeBound(value string, now time.Time, location *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if match := relativeTimeRegexp.FindStringSubmatch(value); match != nil {
+		if match[1] == "" {
+			return now, nil
+		}
+		offset, err := parseInfluxDuration(match[2])
+		if err != nil {
+			return time.Time{}, err
+		}
+		if match[1] == "-" {
+			offset = -offset
+		}
+		return now.Add(offset), nil
+	}
+	if milliseconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(milliseconds), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s", value)
+}
+
+// parseInfluxDuration parses InfluxQL duration literals such as 90s, 1h30m or 2w
+func parseInfluxDuration(value string) (time.Duration, error) {
+	matches := durationPartRegexp.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+	var (
+		total time.Duration
+		last  int
+	)
+	for _, match := range matches {
+		if match[0] != last {
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		number, err := strconv.ParseInt(value[match[2]:match[3]], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		var unit time.Duration
+		switch value[match[4]:match[5]] {
+		case "ns":
+			unit = time.Nanosecond
+		case "us", "u", "µ":
+			unit = time.Microsecond
+		case "ms":
+			unit = time.Millisecond
+		case "s":
+			unit = time.Second
+		case "m":
+			unit = time.Minute
+		case "h":
+			unit = time.Hour
+		case "d":
+			unit = 24 * time.Hour
+		case "w":
+			unit = 7 * 24 * time.Hour
+		}
+		total += time.Duration(number) * unit
+		last = match[1]
+	}
+	if last != len(value) {
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+	return total, nil
+}
+
+// formatInfluxDuration formats d with the largest unit that divides it
+func formatInfluxDuration(d time.Duration) string {
+	for _, unit := range []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"w", 7 * 24 * time.Hour}, {"d", 24 * time.Hour}, {"h", time.Hour}, {"m", time.Minute},
+		{"s", time.Second}, {"ms", time.Millisecond}, {"u", time.Microsecond},
+	} {
+		if d >= unit.size && d%unit.size == 0 {
+			return strconv.FormatInt(int64(d/unit.size), 10) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(int64(d), 10) + "ns"
+}
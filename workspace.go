@@ -0,0 +1,495 @@
+// Copyright 2026 openGemini Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	workspaceBundleVersion = 1
+	workspaceBundleEntry   = "workspace.json"
+
+	WorkspaceFormatJSON = "json"
+	WorkspaceFormatZip  = "zip"
+
+	SecretsStrip   = "strip"
+	SecretsEncrypt = "encrypt"
+
+	ConflictSkip      = "skip"
+	ConflictRename    = "rename"
+	ConflictOverwrite = "overwrite"
+
+	ImportCreated     = "created"
+	ImportSkipped     = "skipped"
+	ImportRenamed     = "renamed"
+	ImportOverwritten = "overwritten"
+
+	secretsAlgorithm = "argon2id+aes-256-gcm"
+	encryptedPrefix  = "enc:"
+)
+
+var (
+	WorkspacePasswordError = errors.New("wrong password or corrupted secrets")
+)
+
+// ExportWorkspace writes the connections, settings, saved queries and optionally the
+// histories to a versioned bundle. Secrets of the connections are stripped, or encrypted
+// with a key derived from the password of the options.
+func (app *App) ExportWorkspace(path string, options *WorkspaceExportOptions) error {
+	if options == nil {
+		options = &WorkspaceExportOptions{}
+	}
+	format, err := workspaceFormat(path, options.Format)
+	if err != nil {
+		return err
+	}
+	bundle := &WorkspaceBundle{
+		Version:    workspaceBundleVersion,
+		ExportedAt: time.Now().UnixMilli(),
+		Secrets:    options.Secrets,
+	}
+	var sealer *secretSealer
+	switch options.Secrets {
+	case "", SecretsStrip:
+		bundle.Secrets = SecretsStrip
+	case SecretsEncrypt:
+		if options.Password == "" {
+			return errors.New("password required to encrypt secrets")
+		}
+		bundle.Encryption, sealer, err = newSecretSealer(options.Password)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported secrets option: %s", options.Secrets)
+	}
+
+	bundle.Connections = app.ListConnects()
+	for _, connect := range bundle.Connections {
+		for _, secret := range connectSecrets(connect) {
+			if *secret == "" {
+				continue
+			}
+			if sealer == nil {
+				*secret = ""
+			} else if *secret, err = sealer.seal(*secret); err != nil {
+				return fmt.Errorf("encrypt secrets failed: %w", err)
+			}
+		}
+	}
+	if bundle.Settings, err = app.GetSetting(); err != nil {
+		return err
+	}
+	if bundle.SavedQueries, err = app.ListSavedQueries(); err != nil {
+		return err
+	}
+	if options.IncludeHistory {
+		if bundle.Histories, err = app.loadHistories(nil); err != nil {
+			return err
+		}
+	}
+
+	content, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	if format == WorkspaceFormatZip {
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		writer, err := archive.Create(workspaceBundleEntry)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(content); err != nil {
+			return err
+		}
+		if err := archive.Close(); err != nil {
+			return err
+		}
+		content = buf.Bytes()
+	}
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		app.logger.Error("export workspace failed", "reason", err, "path", path)
+		return fmt.Errorf("export workspace failed: %w", err)
+	}
+	app.logger.Info("export workspace", "path", path, "connections", len(bundle.Connections),
+		"saved_queries", len(bundle.SavedQueries), "histories", len(bundle.Histories))
+	return nil
+}
+
+// ImportWorkspace reads a bundle written by ExportWorkspace. Connections and saved queries
+// whose name is already taken are skipped, renamed or overwritten as the options say;
+// settings have no name to rename and replace the local ones unless conflicts are skipped.
+// The import is a single transaction, nothing is changed when it fails.
+func (app *App) ImportWorkspace(path string, options *WorkspaceImportOptions) (*WorkspaceImportReport, error) {
+	if options == nil {
+		options = &WorkspaceImportOptions{}
+	}
+	conflict, err := importConflict(options.Conflict)
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := readWorkspaceBundle(path)
+	if err != nil {
+		app.logger.Error("read workspace bundle failed", "reason", err, "path", path)
+		return nil, err
+	}
+	if err := openBundleSecrets(bundle, options.Password); err != nil {
+		return nil, err
+	}
+
+	report := &WorkspaceImportReport{
+		Version:        bundle.Version,
+		Items:          make([]*WorkspaceImportItem, 0),
+		MissingSecrets: make([]string, 0),
+	}
+	err = app.update(func(tx *bolt.Tx) error {
+		if err := importConnections(tx, bundle.Connections, conflict, report); err != nil {
+			return err
+		}
+		if err := importSavedQueries(tx, bundle.SavedQueries, conflict, report); err != nil {
+			return err
+		}
+		if bundle.Settings != nil {
+			if err := importSettings(tx, bundle.Settings, conflict, report); err != nil {
+				return err
+			}
+		}
+		bucket := tx.Bucket([]byte(BucketHistories))
+		for _, history := range bundle.Histories {
+			// IDs are time-ordered, the same ID is the same record
+			if history.ID == "" || bucket.Get([]byte(history.ID)) != nil {
+				continue
+			}
+			content, err := json.Marshal(history)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(history.ID), content); err != nil {
+				return err
+			}
+			report.Histories++
+		}
+		// The history limit of the settings, imported or not, applies as in AddHistory
+		var setting = &AppSetting{}
+		if data := tx.Bucket([]byte(BucketSettings)).Get([]byte("system")); data != nil {
+			if err := json.Unmarshal(data, setting); err != nil {
+				return err
+			}
+		}
+		return trimHistories(bucket, report.Histories, setting.MaxHistoryCount)
+	})
+	if err != nil {
+		app.logger.Error("import workspace failed", "reason", err, "path", path)
+		return nil, fmt.Errorf("import workspace failed: %w", err)
+	}
+	if setting, err := app.GetSetting(); err == nil {
+		app.applySetting(setting)
+	}
+	app.logger.Info("import workspace", "path", path, "items", len(report.Items), "histories", report.Histories)
+	return report, nil
+}
+
+// importConflict checks the conflict option, skip by default
+func importConflict(conflict string) (string, error) {
+	switch conflict {
+	case "":
+		return ConflictSkip, nil
+	case ConflictSkip, ConflictRename, ConflictOverwrite:
+		return conflict, nil
+	}
+	return "", fmt.Errorf("unsupported conflict option: %s", conflict)
+}
+
+func importConnections(tx *bolt.Tx, connects []*ConnectConfig, conflict string, report *WorkspaceImportReport) error {
+	bucket := tx.Bucket([]byte(BucketConnections))
+	for _, connect := range connects {
+		if connect.Name == "" {
+			continue
+		}
+		item := &WorkspaceImportItem{Kind: "connection", Name: connect.Name, Action: ImportCreated}
+		report.Items = append(report.Items, item)
+		if content := bucket.Get([]byte(connect.Name)); content != nil {
+			switch conflict {
+			case ConflictSkip:
+				item.Action = ImportSkipped
+				continue
+			case ConflictRename:
+				connect.Name = freeName(connect.Name, "imported", func(name string) bool {
+					return bucket.Get([]byte(name)) != nil
+				})
+				item.Action, item.NewName = ImportRenamed, connect.Name
+			case ConflictOverwrite:
+				item.Action = ImportOverwritten
+				// Keep the local secrets the bundle does not carry
+				var local = &ConnectConfig{}
+				if err := json.Unmarshal(content, local); err != nil {
+					return err
+				}
+				secrets, localSecrets := connectSecrets(connect), connectSecrets(local)
+				for i, secret := range secrets {
+					if *secret == "" {
+						*secret = *localSecrets[i]
+					}
+				}
+			}
+		}
+		if connect.EnableAuth && connect.Password == "" ||
+			connect.EnableSSH && connect.SSHPassword == "" && connect.SSHKeyPath == "" {
+			report.MissingSecrets = append(report.MissingSecrets, connect.Name)
+		}
+		content, err := json.Marshal(connect)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put([]byte(connect.Name), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func importSavedQueries(tx *bolt.Tx, queries []*SavedQuery, conflict string, report *WorkspaceImportReport) error {
+	bucket := tx.Bucket([]byte(BucketSavedQueries))
+	for _, query := range queries {
+		if err := normalizeSavedQuery(query); err != nil {
+			return fmt.Errorf("saved query %s: %w", query.Name, err)
+		}
+		item := &WorkspaceImportItem{Kind: "saved_query", Name: query.Name, Action: ImportCreated}
+		report.Items = append(report.Items, item)
+
+		// Bundles of other machines have other IDs, queries are matched by folder and name
+		existing, err := findSavedQuery(bucket, query.Folder, query.Name)
+		if err != nil {
+			return err
+		}
+		id, err := randomHex(8)
+		if err != nil {
+			return err
+		}
+		query.ID = id
+		if existing != nil {
+			switch conflict {
+			case ConflictSkip:
+				item.Action = ImportSkipped
+				continue
+			case ConflictRename:
+				query.Name = freeName(query.Name, "imported", func(name string) bool {
+					return checkSavedQueryName(bucket, &SavedQuery{ID: id, Name: name, Folder: query.Folder}) != nil
+				})
+				item.Action, item.NewName = ImportRenamed, query.Name
+			case ConflictOverwrite:
+				query.ID = existing.ID
+				item.Action = ImportOverwritten
+			}
+		}
+		if err := putSavedQuery(bucket, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func importSettings(tx *bolt.Tx, settings *AppSetting, conflict string, report *WorkspaceImportReport) error {
+	item := &WorkspaceImportItem{Kind: "settings", Name: "system", Action: ImportOverwritten}
+	report.Items = append(report.Items, item)
+	if conflict == ConflictSkip {
+		item.Action = ImportSkipped
+		return nil
+	}
+	bucket := tx.Bucket([]byte(BucketSettings))
+	var local = &AppSetting{}
+	if content := bucket.Get([]byte("system")); content != nil {
+		if err := json.Unmarshal(content, local); err != nil {
+			return err
+		}
+	}
+	// The data directory belongs to this machine
+	settings.DataDirectory = local.DataDirectory
+	return bucket.Put([]byte("system"), settings.Marshal())
+}
+
+// findSavedQuery returns the saved query of the folder with the name, nil when there is none
+func findSavedQuery(bucket *bolt.Bucket, folder, name string) (*SavedQuery, error) {
+	var found *SavedQuery
+	err := bucket.ForEach(func(k, v []byte) error {
+		var query = &SavedQuery{}
+		if err := json.Unmarshal(v, query); err != nil {
+			return err
+		}
+		if found == nil && query.Folder == folder && strings.EqualFold(query.Name, name) {
+			found = query
+		}
+		return nil
+	})
+	return found, err
+}
+
+func workspaceFormat(path, format string) (string, error) {
+	switch format {
+	case "":
+		if strings.EqualFold(filepath.Ext(path), ".zip") {
+			return WorkspaceFormatZip, nil
+		}
+		return WorkspaceFormatJSON, nil
+	case WorkspaceFormatJSON, WorkspaceFormatZip:
+		return format, nil
+	}
+	return "", fmt.Errorf("unsupported bundle format: %s", format)
+}
+
+// readWorkspaceBundle reads a JSON bundle, or the bundle entry of a zip archive
+func readWorkspaceBundle(path string) (*WorkspaceBundle, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(content, []byte("PK\x03\x04")) {
+		archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return nil, err
+		}
+		entry, err := archive.Open(workspaceBundleEntry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace bundle: %w", err)
+		}
+		defer entry.Close()
+		if content, err = io.ReadAll(entry); err != nil {
+			return nil, err
+		}
+	}
+	var bundle = &WorkspaceBundle{}
+	if err := json.Unmarshal(content, bundle); err != nil {
+		return nil, fmt.Errorf("invalid workspace bundle: %w", err)
+	}
+	if bundle.Version < 1 || bundle.Version > workspaceBundleVersion {
+		return nil, fmt.Errorf("unsupported workspace bundle version: %d", bundle.Version)
+	}
+	return bundle, nil
+}
+
+// openBundleSecrets decrypts the secrets of the connections of an encrypted bundle
+func openBundleSecrets(bundle *WorkspaceBundle, password string) error {
+	if bundle.Secrets != SecretsEncrypt {
+		return nil
+	}
+	if bundle.Encryption == nil || bundle.Encryption.Algorithm != secretsAlgorithm {
+		return errors.New("unsupported secrets encryption")
+	}
+	if password == "" {
+		return errors.New("password required to decrypt secrets")
+	}
+	sealer, err := openSecretSealer(bundle.Encryption, password)
+	if err != nil {
+		return err
+	}
+	for _, connect := range bundle.Connections {
+		for _, secret := range connectSecrets(connect) {
+			if *secret == "" {
+				continue
+			}
+			if *secret, err = sealer.open(*secret); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// connectSecrets returns the fields of the connection that are not exported in clear
+func connectSecrets(connect *ConnectConfig) []*string {
+	return []*string{&connect.Password, &connect.SSHPassword, &connect.SSHKeyPassphrase}
+}
+
+// secretSealer encrypts values with AES-256-GCM under a key derived by Argon2id
+type secretSealer struct {
+	aead cipher.AEAD
+}
+
+func newSecretSealer(password string) (*WorkspaceEncryption, *secretSealer, error) {
+	var salt = make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, err
+	}
+	encryption := &WorkspaceEncryption{
+		Algorithm: secretsAlgorithm,
+		Salt:      base64.StdEncoding.EncodeToString(salt),
+		Time:      1,
+		Memory:    64 * 1024,
+		Threads:   4,
+	}
+	sealer, err := openSecretSealer(encryption, password)
+	return encryption, sealer, err
+}
+
+func openSecretSealer(encryption *WorkspaceEncryption, password string) (*secretSealer, error) {
+	salt, err := base64.StdEncoding.DecodeString(encryption.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, encryption.Time, encryption.Memory, encryption.Threads, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &secretSealer{aead: aead}, nil
+}
+
+func (s *secretSealer) seal(value string) (string, error) {
+	var nonce = make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *secretSealer) open(value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return "", WorkspacePasswordError
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return "", WorkspacePasswordError
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", WorkspacePasswordError
+	}
+	return string(plain), nil
+}